@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -12,13 +13,24 @@ func main() {
 	log.SetFlags(log.Lshortfile)
 
 	if err := root(os.Args); err != nil {
-		log.Fatalf("Error: %v", err)
+		// Findings are already reported, only the exit status is left
+		var findings *cli.FindingsError
+		if errors.As(err, &findings) {
+			os.Exit(1)
+		}
+		log.Printf("Error: %v", err)
+		os.Exit(2)
 	}
 }
 
 func root(args []string) error {
 	commands := []cli.Command{
 		cli.NewParseCommand(),
+		cli.NewDiffCommand(),
+		cli.NewHistoryCommand(),
+		cli.NewSchemaCommand(),
+		cli.NewExplainCommand(),
+		cli.NewConflictsCommand(),
 	}
 
 	if len(args) < 2 {
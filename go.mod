@@ -2,10 +2,12 @@ module github.com/unLomTrois/gock3
 
 go 1.23
 
-require github.com/fatih/color v1.17.0
+require (
+	github.com/fatih/color v1.17.0
+	github.com/mattn/go-isatty v0.0.20
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
 	golang.org/x/sys v0.18.0 // indirect
 )
@@ -1,5 +1,12 @@
 package cli
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
 type Command interface {
 	Run(args []string) error
 
@@ -9,3 +16,30 @@ type Command interface {
 	// Description for help
 	Description() string
 }
+
+// FindingsError is returned by commands whose diagnostics reach the --fail-on
+// threshold. The diagnostics are already reported, so the process only exits with
+// status 1, leaving status 2 for failures of the tool itself.
+type FindingsError struct {
+	Count     int
+	Threshold severity.Severity
+}
+
+func (e *FindingsError) Error() string {
+	return fmt.Sprintf("%d diagnostic(s) at or above %s", e.Count, strings.ToLower(e.Threshold.String()))
+}
+
+// failOnValues are the accepted values of --fail-on.
+const failOnValues = "info, warning, error, critical or none"
+
+// parseFailOn parses a --fail-on threshold; "none" never fails and returns false.
+func parseFailOn(s string) (severity.Severity, bool, error) {
+	if s == "none" {
+		return 0, false, nil
+	}
+	sev, err := severity.Parse(s)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid --fail-on %q (expected %s)", s, failOnValues)
+	}
+	return sev, true, nil
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/conflicts"
+	"github.com/unLomTrois/gock3/pkg/descriptor"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/workspace"
+)
+
+type ConflictsCommand struct {
+	flagset *flag.FlagSet
+	game    string
+	format  string
+	jobs    int
+	out     io.Writer
+}
+
+// NewConflictsCommand initializes a new ConflictsCommand with the appropriate flags.
+func NewConflictsCommand() *ConflictsCommand {
+	cc := &ConflictsCommand{
+		flagset: flag.NewFlagSet("conflicts", flag.ContinueOnError),
+		out:     os.Stdout,
+	}
+
+	// CLI usage example:
+	//   gock3 conflicts first_mod second_mod.zip --game "Crusader Kings III/game"
+	cc.flagset.StringVar(
+		&cc.game,
+		"game",
+		"",
+		"Game directory, the one containing \"common\", to compare the mods with\nExample: --game \"Crusader Kings III/game\"",
+	)
+	cc.flagset.StringVar(
+		&cc.format,
+		"format",
+		"text",
+		"Output format: text or json\nExample: --format json",
+	)
+	cc.flagset.IntVar(
+		&cc.jobs,
+		"jobs",
+		0,
+		"Number of files parsed in parallel, 0 for one per CPU\nExample: --jobs 4",
+	)
+
+	return cc
+}
+
+// Name returns the name of the command.
+func (cc *ConflictsCommand) Name() string {
+	return cc.flagset.Name()
+}
+
+// Description returns a short description of what the command does.
+func (cc *ConflictsCommand) Description() string {
+	return "Report the files and objects the mods of a load order override"
+}
+
+// Run is the entry point for the 'conflicts' command. It loads the mods in the order
+// given on top of the game and reports what each of them overrides.
+func (cc *ConflictsCommand) Run(args []string) error {
+	paths, err := cc.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// The archives stay open for the report to read their files
+	ws := workspace.New()
+	defer ws.Close()
+
+	var mods []*files.ModRoot
+	for _, path := range paths {
+		mod, err := loadMod(ws, path)
+		if err != nil {
+			return err
+		}
+		mods = append(mods, mod)
+	}
+
+	result, err := conflicts.Find(files.NewGameFS(cc.game, mods...), ws.Paths(), cc.jobs)
+	if err != nil {
+		return fmt.Errorf("failed to find conflicts: %w", err)
+	}
+
+	if cc.format == "json" {
+		enc := json.NewEncoder(cc.out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "\t")
+		return enc.Encode(result)
+	}
+	return result.WriteText(cc.out)
+}
+
+// parseArgs validates and parses the incoming arguments using the command's flagset.
+// It returns the mods given before the flags, in load order.
+func (cc *ConflictsCommand) parseArgs(args []string) ([]string, error) {
+	split := len(args)
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			split = i
+			break
+		}
+	}
+	if split == 0 {
+		return nil, fmt.Errorf("not enough arguments (no mod specified)")
+	}
+
+	if err := cc.flagset.Parse(args[split:]); err != nil {
+		return nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+	if cc.format != "text" && cc.format != "json" {
+		return nil, fmt.Errorf("unknown format %q (expected text or json)", cc.format)
+	}
+	if cc.game != "" {
+		if info, err := os.Stat(cc.game); err != nil {
+			return nil, err
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf("game directory %s is not a directory", cc.game)
+		}
+	}
+
+	return args[:split], nil
+}
+
+// loadMod returns a mod of the load order: a mod folder, the .mod file of the launcher
+// pointing to one, or a zip archive. The replace_path folders of the descriptor apply.
+func loadMod(ws *workspace.Workspace, path string) (*files.ModRoot, error) {
+	if files.IsArchive(path) {
+		mod, err := ws.OpenArchive(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		return mod, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		d, _, err := descriptor.Parse(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mod descriptor: %w", err)
+		}
+		return d.ModRoot(), nil
+	}
+
+	mod := &files.ModRoot{Name: modName(path), Path: path}
+	if d, _, err := descriptor.Parse(filepath.Join(path, descriptor.Filename)); err == nil {
+		mod.ReplacePaths = d.ReplacePaths
+	}
+	return mod, nil
+}
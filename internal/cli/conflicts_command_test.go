@@ -0,0 +1,49 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/cli"
+)
+
+func TestConflictsCommand(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"game/common/traits/00_traits.txt":   "brave = { }\n",
+		"first/descriptor.mod":               "name = \"First\"\nreplace_path = \"common/traits\"\n",
+		"first/common/traits/10_traits.txt":  "brave = { }\n",
+		"second/common/traits/20_traits.txt": "brave = { }\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, second := filepath.Join(dir, "first"), filepath.Join(dir, "second")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "text", args: []string{first, second, "--game", filepath.Join(dir, "game")}},
+		{name: "json without the game", args: []string{first, second, "--format", "json"}},
+		{name: "no mod", args: []string{"--game", filepath.Join(dir, "game")}, wantErr: true},
+		{name: "missing mod", args: []string{filepath.Join(dir, "third")}, wantErr: true},
+		{name: "missing game", args: []string{first, "--game", filepath.Join(dir, "nope")}, wantErr: true},
+		{name: "unknown format", args: []string{first, "--format", "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cli.NewConflictsCommand().Run(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/unLomTrois/gock3/internal/utils"
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/diff"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/suppress"
+	"github.com/unLomTrois/gock3/pkg/workspace"
+)
+
+type DiffCommand struct {
+	flagset *flag.FlagSet
+	format  string
+	color   string
+	out     io.Writer
+}
+
+// NewDiffCommand initializes a new DiffCommand with the appropriate flags.
+func NewDiffCommand() *DiffCommand {
+	dc := &DiffCommand{
+		flagset: flag.NewFlagSet("diff", flag.ContinueOnError),
+		out:     os.Stdout,
+	}
+
+	// CLI usage example:
+	//   gock3 diff old.txt new.txt --format json
+	dc.flagset.StringVar(
+		&dc.format,
+		"format",
+		"text",
+		"Output format: text or json\nExample: --format json",
+	)
+	dc.flagset.StringVar(
+		&dc.color,
+		"color",
+		string(report.ColorAuto),
+		"Color the diagnostics: auto, always or never (auto respects NO_COLOR)\nExample: --color=never",
+	)
+
+	return dc
+}
+
+// Name returns the name of the command.
+func (dc *DiffCommand) Name() string {
+	return dc.flagset.Name()
+}
+
+// Description returns a short description of what the command does.
+func (dc *DiffCommand) Description() string {
+	return "Show structural differences between two files"
+}
+
+// Run is the entry point for the 'diff' command. It parses both files and
+// prints the changes between their ASTs.
+func (dc *DiffCommand) Run(args []string) error {
+	if err := dc.parseArgs(args); err != nil {
+		return err
+	}
+
+	colorMode, err := report.ParseColorMode(dc.color)
+	if err != nil {
+		return err
+	}
+
+	ws := workspace.New()
+	defer ws.Close()
+
+	// Diagnostics go to stderr to keep the diff output machine-readable
+	reporter, err := report.NewReporter(report.FormatText, os.Stderr, report.ReporterOptions{
+		Color: colorMode,
+		Cache: ws.Cache(),
+	})
+	if err != nil {
+		return err
+	}
+
+	oldTree, err := dc.parseFile(ws, reporter, args[0])
+	if err != nil {
+		return err
+	}
+
+	newTree, err := dc.parseFile(ws, reporter, args[1])
+	if err != nil {
+		return err
+	}
+
+	result := diff.Compare(oldTree, newTree, diff.DefaultOptions)
+
+	return dc.print(result)
+}
+
+// parseArgs validates and parses the incoming arguments using the command's flagset.
+func (dc *DiffCommand) parseArgs(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("not enough arguments (expected two files)")
+	}
+
+	// Skip args[0] and args[1] (the file paths) when parsing flags
+	if err := dc.flagset.Parse(args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if dc.format != "text" && dc.format != "json" {
+		return fmt.Errorf("unknown format %q (expected text or json)", dc.format)
+	}
+
+	return nil
+}
+
+// parseFile reads and parses the specified file into an AST structure, and reports
+// its diagnostics.
+func (dc *DiffCommand) parseFile(ws *workspace.Workspace, reporter report.Reporter, path string) (*ast.AST, error) {
+	fullpath, err := utils.FileExists(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, diagnostics, err := parser.ParseParadoxFile(ws.File(fullpath, files.Mod))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	diagnostics = suppress.Apply(tree, diagnostics, parser.Codes())
+	if err := reporter.Report(diagnostics); err != nil {
+		return nil, fmt.Errorf("failed to report diagnostics: %w", err)
+	}
+
+	return tree, nil
+}
+
+// print writes the diff result in the selected format.
+func (dc *DiffCommand) print(result *diff.Result) error {
+	if dc.format == "json" {
+		enc := json.NewEncoder(dc.out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "\t")
+		return enc.Encode(result)
+	}
+
+	return result.WriteText(dc.out)
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+)
+
+type ExplainCommand struct {
+	flagset *flag.FlagSet
+	out     io.Writer
+}
+
+// NewExplainCommand initializes a new ExplainCommand.
+func NewExplainCommand() *ExplainCommand {
+	return &ExplainCommand{
+		flagset: flag.NewFlagSet("explain", flag.ContinueOnError),
+		out:     os.Stdout,
+	}
+}
+
+// Name returns the name of the command.
+func (ec *ExplainCommand) Name() string {
+	return ec.flagset.Name()
+}
+
+// Description returns a short description of what the command does.
+func (ec *ExplainCommand) Description() string {
+	return "Explain a diagnostic code, or list all codes (explain [CODE])"
+}
+
+// Run is the entry point for the 'explain' command. Without arguments it lists
+// every diagnostic code, otherwise it prints the catalog entry of the given code.
+//
+// CLI usage example:
+//
+//	gock3 explain P0003
+func (ec *ExplainCommand) Run(args []string) error {
+	if err := ec.flagset.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if ec.flagset.NArg() == 0 {
+		for _, entry := range codes.All() {
+			fmt.Fprintf(ec.out, "%s  %-8s  %s\n", entry.Code, entry.Severity, entry.Title)
+		}
+		return nil
+	}
+
+	code := codes.Code(ec.flagset.Arg(0))
+	entry, ok := codes.Lookup(code)
+	if !ok {
+		return fmt.Errorf("unknown diagnostic code %q (run 'gock3 explain' to list all codes)", code)
+	}
+
+	fmt.Fprintf(ec.out, "%s: %s\n", entry.Code, entry.Title)
+	fmt.Fprintf(ec.out, "Default severity: %s\n\n", entry.Severity)
+	fmt.Fprintf(ec.out, "%s\n\n", entry.Explanation)
+	fmt.Fprintln(ec.out, "Example:")
+	fmt.Fprintln(ec.out)
+	for _, line := range strings.Split(entry.Example, "\n") {
+		fmt.Fprintf(ec.out, "    %s\n", line)
+	}
+	return nil
+}
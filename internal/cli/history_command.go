@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/unLomTrois/gock3/internal/utils"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/history"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/suppress"
+	"github.com/unLomTrois/gock3/pkg/validator"
+	"github.com/unLomTrois/gock3/pkg/values"
+	"github.com/unLomTrois/gock3/pkg/workspace"
+)
+
+type HistoryCommand struct {
+	flagset *flag.FlagSet
+	at      string
+	format  string
+	color   string
+	out     io.Writer
+}
+
+// NewHistoryCommand initializes a new HistoryCommand with the appropriate flags.
+func NewHistoryCommand() *HistoryCommand {
+	hc := &HistoryCommand{
+		flagset: flag.NewFlagSet("history", flag.ContinueOnError),
+		out:     os.Stdout,
+	}
+
+	// CLI usage example:
+	//   gock3 history characters.txt 70027 --at 1066.9.15
+	hc.flagset.StringVar(
+		&hc.at,
+		"at",
+		"",
+		"Date to resolve the entry at (defaults to after its last event)\nExample: --at 1066.9.15",
+	)
+	hc.flagset.StringVar(
+		&hc.format,
+		"format",
+		"text",
+		"Output format: text or json\nExample: --format json",
+	)
+	hc.flagset.StringVar(
+		&hc.color,
+		"color",
+		string(report.ColorAuto),
+		"Color the diagnostics: auto, always or never (auto respects NO_COLOR)\nExample: --color=never",
+	)
+
+	return hc
+}
+
+// Name returns the name of the command.
+func (hc *HistoryCommand) Name() string {
+	return hc.flagset.Name()
+}
+
+// Description returns a short description of what the command does.
+func (hc *HistoryCommand) Description() string {
+	return "Resolve the state of a history entry at a given date"
+}
+
+// Run is the entry point for the 'history' command. It parses the file, finds
+// the entry and prints its resolved state.
+func (hc *HistoryCommand) Run(args []string) error {
+	if err := hc.parseArgs(args); err != nil {
+		return err
+	}
+
+	colorMode, err := report.ParseColorMode(hc.color)
+	if err != nil {
+		return err
+	}
+
+	fullpath, err := utils.FileExists(args[0])
+	if err != nil {
+		return err
+	}
+
+	ws := workspace.New()
+	defer ws.Close()
+
+	tree, diagnostics, err := parser.ParseParadoxFile(ws.File(fullpath, files.Mod))
+	if err != nil {
+		return fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	timeline, timelineDiagnostics, err := history.Find(tree, args[1])
+	if err != nil {
+		return err
+	}
+	diagnostics = append(diagnostics, timelineDiagnostics...)
+	diagnostics = append(diagnostics, validator.DuplicateKeys(tree.Block, validator.CharacterHistory)...)
+	checked := append(parser.Codes(), codes.InvalidHistoryDate, codes.RepeatedKey)
+	diagnostics = suppress.Apply(tree, diagnostics, checked)
+
+	// Diagnostics go to stderr to keep the state output machine-readable
+	reporter, err := report.NewReporter(report.FormatText, os.Stderr, report.ReporterOptions{
+		Color: colorMode,
+		Cache: ws.Cache(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := reporter.Report(diagnostics); err != nil {
+		return fmt.Errorf("failed to report diagnostics: %w", err)
+	}
+
+	state, err := hc.resolve(timeline)
+	if err != nil {
+		return err
+	}
+
+	return hc.print(state)
+}
+
+// parseArgs validates and parses the incoming arguments using the command's flagset.
+func (hc *HistoryCommand) parseArgs(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("not enough arguments (expected a file and an entry id)")
+	}
+
+	// Skip args[0] and args[1] (the file path and the id) when parsing flags
+	if err := hc.flagset.Parse(args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if hc.format != "text" && hc.format != "json" {
+		return fmt.Errorf("unknown format %q (expected text or json)", hc.format)
+	}
+
+	return nil
+}
+
+// resolve returns the state at the --at date, or after the last event if none is given.
+func (hc *HistoryCommand) resolve(timeline *history.Timeline) (*history.State, error) {
+	if hc.at == "" {
+		return timeline.Latest(), nil
+	}
+
+	date, err := values.ParseDate(hc.at)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --at date: %w", err)
+	}
+
+	return timeline.At(date), nil
+}
+
+// print writes the resolved state in the selected format.
+func (hc *HistoryCommand) print(state *history.State) error {
+	if hc.format == "json" {
+		enc := json.NewEncoder(hc.out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "\t")
+		return enc.Encode(state)
+	}
+
+	return state.WriteText(hc.out)
+}
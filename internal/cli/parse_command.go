@@ -1,40 +1,167 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/unLomTrois/gock3/internal/utils"
 	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/baseline"
+	"github.com/unLomTrois/gock3/pkg/config"
+	"github.com/unLomTrois/gock3/pkg/descriptor"
 	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/filetypes"
+	"github.com/unLomTrois/gock3/pkg/fix"
 	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/suppress"
+	"github.com/unLomTrois/gock3/pkg/validator"
+	"github.com/unLomTrois/gock3/pkg/workspace"
 )
 
 type ParseCommand struct {
-	flagset     *flag.FlagSet
-	astFilepath string
+	flagset       *flag.FlagSet
+	astFilepath   string
+	format        string
+	root          string
+	color         string
+	fix           bool
+	dryRun        bool
+	renames       string
+	baseline      string
+	writeBaseline string
+	config        string
+	failOn        string
+	maxPerFile    int
+	jobs          int
+	timings       bool
+	absolutePaths bool
+	out           io.Writer
+	// errOut receives the summary when out holds machine-readable output.
+	errOut io.Writer
 }
 
 // NewParseCommand initializes a new ParseCommand with the appropriate flags.
 func NewParseCommand() *ParseCommand {
 	pc := &ParseCommand{
 		flagset: flag.NewFlagSet("parse", flag.ContinueOnError),
+		out:     os.Stdout,
+		errOut:  os.Stderr,
 	}
 
 	// CLI usage example:
-	//   gock3 parse file.txt --save-ast ast.json
+	//   gock3 parse file.txt --save-ast ast.json --format json
 	pc.flagset.StringVar(
 		&pc.astFilepath,
 		"save-ast",
 		"",
 		"Save the AST to a file\nExample: --save-ast ast.json",
 	)
+	pc.flagset.StringVar(
+		&pc.format,
+		"format",
+		report.FormatText,
+		"Diagnostics output format: text, json, jsonl or sarif\nExample: --format sarif",
+	)
+	pc.flagset.StringVar(
+		&pc.root,
+		"root",
+		".",
+		"Mod root directory, file locations are shown relative to it\nExample: --root ./my_mod",
+	)
+	pc.flagset.StringVar(
+		&pc.color,
+		"color",
+		string(report.ColorAuto),
+		"Color the text output: auto, always or never (auto respects NO_COLOR)\nExample: --color=never",
+	)
+	pc.flagset.BoolVar(
+		&pc.fix,
+		"fix",
+		false,
+		"Apply the suggested fixes to the file, overlapping fixes are left for the next run",
+	)
+	pc.flagset.BoolVar(
+		&pc.dryRun,
+		"dry-run",
+		false,
+		"With --fix, print the fixes as a unified diff instead of writing the file",
+	)
+	pc.flagset.StringVar(
+		&pc.renames,
+		"renames",
+		"",
+		"JSON object mapping deprecated keys to their replacements\nExample: --renames renames.json",
+	)
+	pc.flagset.StringVar(
+		&pc.baseline,
+		"baseline",
+		"",
+		"Only report diagnostics missing from a baseline file, files are matched relative to --root\nExample: --baseline gock3-baseline.json",
+	)
+	pc.flagset.StringVar(
+		&pc.writeBaseline,
+		"write-baseline",
+		"",
+		"Record the current diagnostics in a baseline file instead of reporting them\nExample: --write-baseline gock3-baseline.json",
+	)
+	pc.flagset.StringVar(
+		&pc.config,
+		"config",
+		"",
+		"Configuration file with severity overrides, defaults to "+config.Filename+" in the root\nExample: --config gock3.json",
+	)
+	pc.flagset.StringVar(
+		&pc.failOn,
+		"fail-on",
+		"error",
+		"Exit with status 1 if a diagnostic has this severity or higher: "+failOnValues+"\nExample: --fail-on=warning",
+	)
+
+	pc.flagset.IntVar(
+		&pc.maxPerFile,
+		"max-per-file",
+		100,
+		"Report at most this many diagnostics per file, 0 for all of them\nExample: --max-per-file 20",
+	)
+	pc.flagset.IntVar(
+		&pc.jobs,
+		"jobs",
+		0,
+		"Number of files parsed in parallel, 0 for one per CPU\nExample: --jobs 4",
+	)
+	pc.flagset.BoolVar(
+		&pc.absolutePaths,
+		"absolute-paths",
+		false,
+		"Show the full paths of the files instead of \"[mod] common/...\" paths relative to --root",
+	)
+	pc.flagset.BoolVar(
+		&pc.timings,
+		"timings",
+		false,
+		"Print the parse time of each file, slowest first, to stderr",
+	)
 
 	return pc
 }
 
+// SetOutput makes the command write its report and diffs to out, and the summary
+// of machine-readable reports to errOut, instead of the standard output and error.
+func (pc *ParseCommand) SetOutput(out, errOut io.Writer) {
+	pc.out, pc.errOut = out, errOut
+}
+
 // Name returns the name of the command.
 func (pc *ParseCommand) Name() string {
 	return pc.flagset.Name()
@@ -42,61 +169,472 @@ func (pc *ParseCommand) Name() string {
 
 // Description returns a short description of what the command does.
 func (pc *ParseCommand) Description() string {
-	return "Parse a file and generate the output files"
+	return "Parse files, directories or glob patterns and report diagnostics"
+}
+
+// parsedFile is a file given to the command with its AST and the diagnostics left
+// to report.
+type parsedFile struct {
+	// path is the path as given or found in a given directory, fullpath the absolute one.
+	path     string
+	fullpath string
+	file     files.ParadoxFile
+	// archived tells whether the file is in a zip archive, which can't be fixed.
+	archived    bool
+	ast         *ast.AST
+	diagnostics []*report.DiagnosticItem
+	duration    time.Duration
 }
 
-// Run is the entry point for the 'parse' command. It parses the arguments,
-// validates them, reads the file, and handles the resulting AST.
+// Run is the entry point for the 'parse' command. It parses the arguments, finds
+// the files to parse, parses them in parallel and reports the diagnostics of all
+// of them.
 func (pc *ParseCommand) Run(args []string) error {
 	// 1. Parse the CLI arguments
-	if err := pc.parseArgs(args); err != nil {
+	patterns, err := pc.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	paths, err := files.Discover(patterns...)
+	if err != nil {
 		return err
 	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no %s files found in %s", files.ScriptExt, strings.Join(patterns, ", "))
+	}
 
-	filePath := args[0]
-	fullpath, err := utils.FileExists(filePath)
+	colorMode, err := report.ParseColorMode(pc.color)
 	if err != nil {
 		return err
 	}
 
-	// 2. Parse the file to get the AST
-	ast, err := pc.parseFile(fullpath)
+	// The archives stay open for the diagnostics to show their files
+	ws := workspace.New()
+	defer ws.Close()
+
+	reporter, err := report.NewReporter(pc.format, pc.out, report.ReporterOptions{
+		Root:          pc.root,
+		Color:         colorMode,
+		AbsolutePaths: pc.absolutePaths,
+		Cache:         ws.Cache(),
+	})
 	if err != nil {
 		return err
 	}
 
+	threshold, failOn, err := parseFailOn(pc.failOn)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := pc.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	renames, err := pc.loadRenames()
+	if err != nil {
+		return err
+	}
+
+	// 2. Parse the files to get their ASTs
+	start := time.Now()
+	parsed, failed, err := pc.parseFiles(ws, paths, renames)
+	if err != nil {
+		return err
+	}
+	if len(parsed) == 0 && len(failed) > 0 {
+		return errors.Join(failed...)
+	}
+	if len(parsed) == 0 {
+		return fmt.Errorf("no %s files found in %s", files.ScriptExt, strings.Join(patterns, ", "))
+	}
+	if pc.astFilepath != "" && len(parsed) > 1 {
+		return fmt.Errorf("--save-ast requires a single file, got %d", len(parsed))
+	}
+	if err := pc.writeTimings(parsed, time.Since(start)); err != nil {
+		return err
+	}
+
+	for _, file := range parsed {
+		file.diagnostics = cfg.Apply(file.diagnostics)
+		if pc.fix && file.archived {
+			if hasFixes(file.diagnostics) {
+				log.Printf("Skipped fixes in %s, files in archives can't be fixed", file.path)
+			}
+		} else if pc.fix {
+			fixed, err := pc.applyFixes(ws, file)
+			if err != nil {
+				return err
+			}
+			if fixed {
+				// The fixes moved the code after them, the file is checked again
+				if err := pc.recheck(file, renames); err != nil {
+					return err
+				}
+				file.diagnostics = cfg.Apply(file.diagnostics)
+			}
+		}
+	}
+	if pc.fix && pc.dryRun {
+		// The diff is the output
+		return readError(failed, len(parsed))
+	}
+
+	if err := pc.handleBaseline(parsed); err != nil {
+		return err
+	}
+
+	collector := report.NewCollector(pc.maxPerFile)
+	for _, file := range parsed {
+		collector.Add(file.diagnostics...)
+	}
+	if err := reporter.Report(collector.Diagnostics()); err != nil {
+		return fmt.Errorf("failed to report diagnostics: %w", err)
+	}
+	summary := collector.Summary()
+	if err := pc.writeSummary(summary); err != nil {
+		return err
+	}
+
 	// 3. Handle the AST (save to file if needed)
-	if err := pc.handleAST(ast); err != nil {
+	if err := pc.handleAST(parsed[0].ast); err != nil {
 		return err
 	}
 
+	// Files that couldn't be read fail the command after the others are reported
+	if err := readError(failed, len(parsed)); err != nil {
+		return err
+	}
+	if n := summary.AtLeast(threshold); failOn && n > 0 {
+		return &FindingsError{Count: n, Threshold: threshold}
+	}
 	return nil
 }
 
+// readError returns an error counting the files that couldn't be read, nil if there
+// are none. The errors themselves are logged by parseFiles.
+func readError(failed []error, parsed int) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to read %d of %d file(s)", len(failed), len(failed)+parsed)
+}
+
+// writeTimings writes the parse time of each file, slowest first, with --timings.
+func (pc *ParseCommand) writeTimings(parsed []*parsedFile, total time.Duration) error {
+	if !pc.timings {
+		return nil
+	}
+
+	slowest := append([]*parsedFile{}, parsed...)
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].duration > slowest[j].duration })
+
+	var b strings.Builder
+	for _, file := range slowest {
+		fmt.Fprintf(&b, "%10s  %s\n", file.duration.Round(time.Microsecond), file.path)
+	}
+	fmt.Fprintf(&b, "Parsed %d file(s) in %s\n", len(parsed), total.Round(time.Microsecond))
+	if _, err := io.WriteString(pc.errOut, b.String()); err != nil {
+		return fmt.Errorf("failed to write timings: %w", err)
+	}
+	return nil
+}
+
+// writeSummary writes the counts of diagnostics per severity after the text output,
+// or to errOut so it doesn't mix with machine-readable output.
+func (pc *ParseCommand) writeSummary(summary report.Summary) error {
+	w := pc.errOut
+	if pc.format == report.FormatText {
+		w = pc.out
+	}
+	if _, err := fmt.Fprintf(w, "Summary: %s\n", summary); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
+
+// loadConfig reads the --config file, or the configuration file of the root.
+func (pc *ParseCommand) loadConfig() (*config.Config, error) {
+	if pc.config != "" {
+		return config.Load(pc.config)
+	}
+	return config.Discover(pc.root)
+}
+
 // parseArgs validates and parses the incoming arguments using the command's flagset.
-func (pc *ParseCommand) parseArgs(args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("not enough arguments (no file specified)")
+// It returns the files, directories and patterns given before the flags.
+func (pc *ParseCommand) parseArgs(args []string) ([]string, error) {
+	split := len(args)
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			split = i
+			break
+		}
+	}
+	if split == 0 {
+		return nil, fmt.Errorf("not enough arguments (no file specified)")
+	}
+
+	if err := pc.flagset.Parse(args[split:]); err != nil {
+		return nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+	if pc.dryRun && !pc.fix {
+		return nil, fmt.Errorf("--dry-run requires --fix")
+	}
+
+	return args[:split], nil
+}
+
+// loadRenames reads the table of deprecated keys given with --renames.
+func (pc *ParseCommand) loadRenames() (map[string]string, error) {
+	if pc.renames == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(pc.renames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read renames: %w", err)
+	}
+	var renames map[string]string
+	if err := json.Unmarshal(data, &renames); err != nil {
+		return nil, fmt.Errorf("failed to parse renames %s: %w", pc.renames, err)
+	}
+	return renames, nil
+}
+
+// parseFiles reads and parses the files in parallel, then runs the validators and
+// applies the suppression comments. Zip archives are opened as mods in the workspace
+// and their script files parsed. Files that can't be read are logged and returned as
+// failed, without stopping the others.
+func (pc *ParseCommand) parseFiles(ws *workspace.Workspace, paths []string, renames map[string]string) ([]*parsedFile, []error, error) {
+	ws.AddRoot(files.Root{Name: modName(pc.root), Path: pc.root})
+	inMod := isMod(pc.root)
+
+	var paradoxFiles []files.ParadoxFile
+	var given []string
+	archived := make(map[files.ParadoxFile]bool)
+	for _, path := range paths {
+		fullpath, err := utils.FileExists(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !files.IsArchive(path) {
+			paradoxFiles = append(paradoxFiles, ws.File(fullpath, files.Mod))
+			given = append(given, path)
+			continue
+		}
+
+		mod, err := ws.OpenArchive(fullpath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		modFiles, err := ws.ModFiles(mod)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		for _, file := range modFiles {
+			paradoxFiles = append(paradoxFiles, file)
+			given = append(given, file.FullPath())
+			archived[file] = true
+		}
 	}
 
-	// Skip args[0] (the file path) when parsing flags
-	if err := pc.flagset.Parse(args[1:]); err != nil {
-		return fmt.Errorf("failed to parse flags: %w", err)
+	results := parser.ParseFiles(paradoxFiles, pc.jobs)
+	parsed := make([]*parsedFile, 0, len(results))
+	var failed []error
+	for i, result := range results {
+		err := result.Err
+		var diagnostics []*report.DiagnosticItem
+		if err == nil {
+			diagnostics, err = checkFile(result.File, result.AST, result.Diagnostics, renames, inMod || archived[result.File])
+		}
+		if err != nil {
+			err = fmt.Errorf("%s: %w", given[i], err)
+			log.Print(err)
+			failed = append(failed, err)
+			continue
+		}
+
+		parsed = append(parsed, &parsedFile{
+			path:        given[i],
+			fullpath:    result.File.FullPath(),
+			file:        result.File,
+			archived:    archived[result.File],
+			ast:         result.AST,
+			diagnostics: diagnostics,
+			duration:    result.Duration,
+		})
 	}
+	return parsed, failed, nil
+}
 
+// recheck parses a file again after its fixes were written, replacing its AST and
+// diagnostics.
+func (pc *ParseCommand) recheck(file *parsedFile, renames map[string]string) error {
+	tree, parseDiagnostics, err := parser.ParseParadoxFile(file.file)
+	if err != nil {
+		return fmt.Errorf("failed to parse fixed file: %w", err)
+	}
+	diagnostics, err := checkFile(file.file, tree, parseDiagnostics, renames, isMod(pc.root))
+	if err != nil {
+		return err
+	}
+	file.ast, file.diagnostics = tree, diagnostics
 	return nil
 }
 
-// parseFile reads and parses the specified file into an AST structure.
-func (pc *ParseCommand) parseFile(fullpath string) (*ast.AST, error) {
-	file := files.NewParadoxTxtFile(fullpath, files.FileKind(files.Mod))
+// checkFile runs the validators on a parsed file, after the diagnostics of the
+// parser, and applies the suppression comments.
+func checkFile(file files.ParadoxFile, tree *ast.AST, diagnostics []*report.DiagnosticItem, renames map[string]string, inMod bool) ([]*report.DiagnosticItem, error) {
+	typeDiagnostics, checked, err := checkFileType(file, tree, inMod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check file: %w", err)
+	}
+	checked = append(checked, parser.Codes()...)
+
+	if len(renames) > 0 {
+		diagnostics = append(diagnostics, validator.DeprecatedKeys(tree.Block, renames)...)
+		checked = append(checked, codes.DeprecatedKey)
+	}
+	diagnostics = append(diagnostics, typeDiagnostics...)
+	return suppress.Apply(tree, diagnostics, checked), nil
+}
+
+// checkFileType runs the checks of the type of a file, found by its path relative to
+// its root, see filetypes, and returns the codes they report. Files outside of any
+// root have no type, and whether the game reads a file is only checked if its root
+// is a mod.
+func checkFileType(file files.ParadoxFile, tree *ast.AST, inMod bool) ([]*report.DiagnosticItem, []codes.Code, error) {
+	idx := file.StoreInPathTable()
+	if _, ok := idx.Root(); !ok {
+		return nil, nil, nil
+	}
+	rel, err := idx.Relpath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checked := filetypes.Default.Codes(rel)
+	if !inMod {
+		diagnostics, err := filetypes.Default.CheckContent(file, rel, tree)
+		return diagnostics, checked, err
+	}
+	diagnostics, err := filetypes.Default.Check(file, rel, tree)
+	return diagnostics, append(checked, codes.UnreadFile), err
+}
+
+// isMod reports whether a directory is a mod, i.e. has a descriptor.mod. Whether the
+// game reads a file is only known for the files of a mod.
+func isMod(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, descriptor.Filename))
+	return err == nil
+}
+
+// modName returns the name of the mod in a directory, from its descriptor.mod, or the
+// name of the directory.
+func modName(dir string) string {
+	if d, _, err := descriptor.Parse(filepath.Join(dir, descriptor.Filename)); err == nil && d.Name != "" {
+		return d.Name
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return filepath.Base(abs)
+	}
+	return filepath.Base(dir)
+}
+
+// hasFixes reports whether a diagnostic has a fix.
+func hasFixes(diagnostics []*report.DiagnosticItem) bool {
+	for _, diag := range diagnostics {
+		if len(diag.Fixes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFixes applies the fixes of the diagnostics to the file, or prints them as a
+// unified diff with --dry-run. It reports whether the file was changed.
+func (pc *ParseCommand) applyFixes(ws *workspace.Workspace, file *parsedFile) (bool, error) {
+	var fixes []*report.Fix
+	for _, diag := range file.diagnostics {
+		fixes = append(fixes, diag.Fixes...)
+	}
+	if len(fixes) == 0 {
+		return false, nil
+	}
+
+	idx := *file.file.StoreInPathTable()
+	content, err := ws.Cache().ReadFile(idx)
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+	result := fix.Apply(content, fixes)
+
+	if pc.dryRun {
+		// Prefixed like git diff, so relative paths can be applied with "git apply"
+		oldName, newName := file.path, file.path
+		if !filepath.IsAbs(file.path) {
+			oldName, newName = "a/"+filepath.ToSlash(file.path), "b/"+filepath.ToSlash(file.path)
+		}
+		if err := fix.UnifiedDiff(pc.out, oldName, newName, content, result.Content); err != nil {
+			return false, fmt.Errorf("failed to write diff: %w", err)
+		}
+		return false, nil
+	}
+	if len(result.Applied) == 0 {
+		return false, nil
+	}
 
-	ast, err := parser.ParseParadoxFile(file)
+	info, err := os.Stat(file.fullpath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse file: %w", err)
+		return false, fmt.Errorf("failed to stat file: %w", err)
+	}
+	err = os.WriteFile(file.fullpath, result.Content, info.Mode().Perm())
+	ws.Cache().Invalidate(idx)
+	if err != nil {
+		return false, fmt.Errorf("failed to write fixes: %w", err)
+	}
+	log.Printf("Fixed %d problem(s) in %s", len(result.Applied), file.path)
+	return true, nil
+}
+
+// handleBaseline records the diagnostics of all files with --write-baseline, or hides
+// the ones recorded in the --baseline file.
+func (pc *ParseCommand) handleBaseline(parsed []*parsedFile) error {
+	if pc.writeBaseline != "" {
+		b := baseline.New(pc.root)
+		count := 0
+		for _, file := range parsed {
+			b.Add(file.ast, file.diagnostics)
+			count += len(file.diagnostics)
+			file.diagnostics = nil
+		}
+		if err := b.Save(pc.writeBaseline); err != nil {
+			return fmt.Errorf("failed to save baseline: %w", err)
+		}
+		log.Printf("Saved %d diagnostic(s) to baseline %s", count, pc.writeBaseline)
+		return nil
 	}
 
-	return ast, nil
+	if pc.baseline == "" {
+		return nil
+	}
+	b, err := baseline.Load(pc.baseline, pc.root)
+	if err != nil {
+		return fmt.Errorf("failed to load baseline: %w", err)
+	}
+	hidden := 0
+	for _, file := range parsed {
+		kept := b.Filter(file.ast, file.diagnostics)
+		hidden += len(file.diagnostics) - len(kept)
+		file.diagnostics = kept
+	}
+	if hidden > 0 {
+		log.Printf("Baseline hides %d known diagnostic(s)", hidden)
+	}
+	return nil
 }
 
 // handleAST handles the logic for the parsed AST, such as saving it to disk.
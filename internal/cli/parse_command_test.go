@@ -1,10 +1,16 @@
 package cli_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/unLomTrois/gock3/internal/cli"
@@ -48,7 +54,7 @@ func TestParseCommand_Name(t *testing.T) {
 
 func TestParseCommand_Description(t *testing.T) {
 	cmd := cli.NewParseCommand()
-	expectedDesc := "Parse a file and generate the output files"
+	expectedDesc := "Parse files, directories or glob patterns and report diagnostics"
 	if cmd.Description() != expectedDesc {
 		t.Errorf("ParseCommand.Description() = %v, want %v", cmd.Description(), expectedDesc)
 	}
@@ -177,6 +183,27 @@ func TestParseCommand_FileParseFailure(t *testing.T) {
 	}
 }
 
+func TestParseCommand_UnreadableFileInDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "00_traits.txt"), []byte("brave = { }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A link to a directory is found as a file, but can't be read as one
+	if err := os.Symlink(t.TempDir(), filepath.Join(dir, "01_traits.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	var findings *cli.FindingsError
+	err := cli.NewParseCommand().Run([]string{dir})
+	if err == nil || errors.As(err, &findings) || !strings.Contains(err.Error(), "1 of 2") {
+		t.Errorf("got %v, want an error for 1 of 2 files", err)
+	}
+}
+
 // TestParseCommand_SaveASTFailure simulates a failure in handleAST.
 // We make the output path unwritable or invalid.
 func TestParseCommand_SaveASTFailure(t *testing.T) {
@@ -207,3 +234,263 @@ func TestParseCommand_SaveASTFailure(t *testing.T) {
 
 // If you want to precisely confirm the returned error messages,
 // you can do so with string checks or by using `errors.As/Is`.
+
+func TestParseCommand_UnknownFormat(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = tmpFile.WriteString("hello = world")
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	cmd := cli.NewParseCommand()
+	err = cmd.Run([]string{tmpFile.Name(), "--format", "xml"})
+	if err == nil {
+		t.Errorf("expected error for unknown format, got nil")
+	}
+}
+
+func TestParseCommand_Fix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "00_traits.txt")
+	if err := os.WriteFile(path, []byte("brave = {\n\told_key == yes\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	renames := filepath.Join(dir, "renames.json")
+	if err := os.WriteFile(renames, []byte(`{"old_key": "new_key"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run leaves the file unchanged
+	if err := cli.NewParseCommand().Run([]string{path, "--fix", "--dry-run", "--renames", renames}); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "brave = {\n\told_key == yes\n" {
+		t.Errorf("dry run modified the file: %q", got)
+	}
+
+	if err := cli.NewParseCommand().Run([]string{path, "--fix", "--renames", renames}); err != nil {
+		t.Fatalf("fix: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "brave = {\n\tnew_key = yes\n}\n"; string(got) != want {
+		t.Errorf("fixed file = %q, want %q", got, want)
+	}
+	if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want it preserved", info.Mode().Perm())
+	}
+}
+
+func TestParseCommand_FixPositions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "00_traits.txt")
+	if err := os.WriteFile(path, []byte("brave = { old_key = yes cost = 0.12345 }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	renames := filepath.Join(dir, "renames.json")
+	if err := os.WriteFile(renames, []byte(`{"old_key": "brand_new_key"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	pc := cli.NewParseCommand()
+	pc.SetOutput(&output, io.Discard)
+	if err := pc.Run([]string{path, "--fix", "--renames", renames, "--format", "json"}); err != nil {
+		t.Fatalf("fix: %v", err)
+	}
+	data := output.Bytes()
+
+	var diagnostics []struct {
+		Code   string `json:"code"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	}
+	if err := json.Unmarshal(data, &diagnostics); err != nil {
+		t.Fatalf("invalid output: %v\n%s", err, data)
+	}
+
+	// The precision warning moved with the renamed key
+	if len(diagnostics) != 1 || diagnostics[0].Code != "P0010" || diagnostics[0].Line != 1 || diagnostics[0].Column != 38 {
+		t.Errorf("diagnostics = %+v, want P0010 at 1:38", diagnostics)
+	}
+}
+
+func TestParseCommand_DryRunWithoutFix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte("hello = world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.NewParseCommand().Run([]string{path, "--dry-run"}); err == nil {
+		t.Errorf("expected an error for --dry-run without --fix")
+	}
+}
+
+func TestParseCommand_Baseline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "00_traits.txt")
+	if err := os.WriteFile(path, []byte("brave = {\n\tcost = 0.12345\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	baselinePath := filepath.Join(dir, "baseline.json")
+
+	if err := cli.NewParseCommand().Run([]string{path, "--root", dir, "--write-baseline", baselinePath}); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+	data, err := os.ReadFile(baselinePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"file": "00_traits.txt"`) || !strings.Contains(string(data), `"code": "P0010"`) {
+		t.Errorf("baseline doesn't record the diagnostic:\n%s", data)
+	}
+
+	if err := cli.NewParseCommand().Run([]string{path, "--root", dir, "--baseline", baselinePath}); err != nil {
+		t.Fatalf("with baseline: %v", err)
+	}
+	if err := cli.NewParseCommand().Run([]string{path, "--baseline", filepath.Join(dir, "missing.json")}); err == nil {
+		t.Errorf("expected an error for a missing baseline")
+	}
+}
+
+func TestParseCommand_FailOn(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "00_traits.txt")
+	if err := os.WriteFile(path, []byte("brave = {\n\tcost = 0.12345\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The precision warning is below the default threshold
+	if err := cli.NewParseCommand().Run([]string{path, "--root", dir}); err != nil {
+		t.Errorf("default threshold: %v", err)
+	}
+
+	var findings *cli.FindingsError
+	err := cli.NewParseCommand().Run([]string{path, "--root", dir, "--fail-on", "warning"})
+	if !errors.As(err, &findings) || findings.Count != 1 {
+		t.Errorf("--fail-on=warning: got %v, want a FindingsError with 1 diagnostic", err)
+	}
+
+	// Raised by the configuration of the root
+	if err := os.WriteFile(filepath.Join(dir, ".gock3.json"), []byte(`{"severity": {"P0010": "error"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.NewParseCommand().Run([]string{path, "--root", dir}); !errors.As(err, &findings) {
+		t.Errorf("severity override: got %v, want a FindingsError", err)
+	}
+
+	if err := cli.NewParseCommand().Run([]string{path, "--root", dir, "--fail-on", "none"}); err != nil {
+		t.Errorf("--fail-on=none: %v", err)
+	}
+	if err := cli.NewParseCommand().Run([]string{path, "--fail-on", "sometimes"}); err == nil || errors.As(err, &findings) {
+		t.Errorf("expected a usage error for an invalid --fail-on, got %v", err)
+	}
+}
+
+func TestParseCommand_Directory(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"common/traits/00_traits.txt":       "brave = {\n\tcost = 0.12345\n}\n",
+		"common/traits/01_traits.txt":       "craven = {\n\tcost = 0.54321\n}\n",
+		"common/religions/00_religions.txt": "catholic = {\n\tcost = 0.11111\n}\n",
+		"common/traits/readme.md":           "not = {",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var findings *cli.FindingsError
+	err := cli.NewParseCommand().Run([]string{filepath.Join(dir, "common"), "--fail-on", "warning", "--jobs", "2"})
+	if !errors.As(err, &findings) || findings.Count != 3 {
+		t.Errorf("directory: got %v, want a FindingsError with 3 diagnostics", err)
+	}
+
+	err = cli.NewParseCommand().Run([]string{filepath.Join(dir, "common", "*", "00_*.txt"), "--fail-on", "warning"})
+	if !errors.As(err, &findings) || findings.Count != 2 {
+		t.Errorf("glob: got %v, want a FindingsError with 2 diagnostics", err)
+	}
+
+	baselinePath := filepath.Join(dir, "baseline.json")
+	if err := cli.NewParseCommand().Run([]string{filepath.Join(dir, "common"), "--root", dir, "--write-baseline", baselinePath}); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+	data, err := os.ReadFile(baselinePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"common/religions/00_religions.txt", "common/traits/00_traits.txt", "common/traits/01_traits.txt"} {
+		if !strings.Contains(string(data), `"file": "`+file+`"`) {
+			t.Errorf("baseline doesn't record %s:\n%s", file, data)
+		}
+	}
+
+	if err := cli.NewParseCommand().Run([]string{filepath.Join(dir, "common"), "--save-ast", filepath.Join(dir, "ast.json")}); err == nil {
+		t.Errorf("expected an error for --save-ast with several files")
+	}
+	if err := cli.NewParseCommand().Run([]string{filepath.Join(dir, "gfx", "*.txt")}); err == nil {
+		t.Errorf("expected an error for a pattern without matches")
+	}
+}
+
+func TestParseCommand_Archive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "better_traits.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"better_traits/common/traits/00_traits.txt": "brave = {\n\tcost = 0.12345\n}\n",
+		"better_traits/common/traits/01_traits.txt": "craven = {\n\tcost = 0.54321\n}\n",
+	} {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var findings *cli.FindingsError
+	err = cli.NewParseCommand().Run([]string{path, "--fail-on", "warning", "--fix"})
+	if !errors.As(err, &findings) || findings.Count != 2 {
+		t.Errorf("got %v, want a FindingsError with 2 diagnostics", err)
+	}
+}
+
+func TestParseCommand_UnreadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "00_traits.txt")
+	if err := os.WriteFile(path, []byte("brave = { }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a descriptor.mod the root may not be a mod
+	if err := cli.NewParseCommand().Run([]string{path, "--root", dir, "--fail-on", "warning"}); err != nil {
+		t.Errorf("no descriptor.mod: %v", err)
+	}
+
+	// The game doesn't read script files at the root of the mod
+	if err := os.WriteFile(filepath.Join(dir, "descriptor.mod"), []byte("name = \"Better Traits\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var findings *cli.FindingsError
+	err := cli.NewParseCommand().Run([]string{path, "--root", dir, "--fail-on", "warning"})
+	if !errors.As(err, &findings) || findings.Count != 1 {
+		t.Errorf("got %v, want a FindingsError with 1 diagnostic", err)
+	}
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/unLomTrois/gock3/internal/utils"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/schema"
+	"github.com/unLomTrois/gock3/pkg/suppress"
+	"github.com/unLomTrois/gock3/pkg/workspace"
+)
+
+type SchemaCommand struct {
+	flagset    *flag.FlagSet
+	schemaPath string
+	goPath     string
+	goPackage  string
+	goType     string
+	color      string
+	out        io.Writer
+}
+
+// NewSchemaCommand initializes a new SchemaCommand with the appropriate flags.
+func NewSchemaCommand() *SchemaCommand {
+	sc := &SchemaCommand{
+		flagset: flag.NewFlagSet("schema", flag.ContinueOnError),
+		out:     os.Stdout,
+	}
+
+	// CLI usage example:
+	//   gock3 schema infer 00_traits.txt 01_traits.txt --out schema.json --go traits.go --type Trait
+	sc.flagset.StringVar(
+		&sc.schemaPath,
+		"out",
+		"",
+		"Save the inferred schema to a file (printed to stdout otherwise)\nExample: --out schema.json",
+	)
+	sc.flagset.StringVar(
+		&sc.goPath,
+		"go",
+		"",
+		"Save Go type definitions to a file\nExample: --go traits.go",
+	)
+	sc.flagset.StringVar(
+		&sc.goPackage,
+		"package",
+		"",
+		"Package name of the generated Go code (defaults to the output directory name)\nExample: --package traits",
+	)
+	sc.flagset.StringVar(
+		&sc.goType,
+		"type",
+		"Entry",
+		"Name of the generated type for top-level entries\nExample: --type Trait",
+	)
+	sc.flagset.StringVar(
+		&sc.color,
+		"color",
+		string(report.ColorAuto),
+		"Color the diagnostics: auto, always or never (auto respects NO_COLOR)\nExample: --color=never",
+	)
+
+	return sc
+}
+
+// Name returns the name of the command.
+func (sc *SchemaCommand) Name() string {
+	return sc.flagset.Name()
+}
+
+// Description returns a short description of what the command does.
+func (sc *SchemaCommand) Description() string {
+	return "Infer a schema and Go types from sample files (schema infer <files...>)"
+}
+
+// Run is the entry point for the 'schema' command.
+func (sc *SchemaCommand) Run(args []string) error {
+	paths, err := sc.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	colorMode, err := report.ParseColorMode(sc.color)
+	if err != nil {
+		return err
+	}
+
+	ws := workspace.New()
+	defer ws.Close()
+
+	reporter, err := report.NewReporter(report.FormatText, os.Stderr, report.ReporterOptions{
+		Color: colorMode,
+		Cache: ws.Cache(),
+	})
+	if err != nil {
+		return err
+	}
+
+	inferrer := schema.NewInferrer()
+	for _, path := range paths {
+		fullpath, err := utils.FileExists(path)
+		if err != nil {
+			return err
+		}
+
+		tree, diagnostics, err := parser.ParseParadoxFile(ws.File(fullpath, files.Mod))
+		if err != nil {
+			return fmt.Errorf("failed to parse file: %w", err)
+		}
+		diagnostics = suppress.Apply(tree, diagnostics, parser.Codes())
+		if err := reporter.Report(diagnostics); err != nil {
+			return fmt.Errorf("failed to report diagnostics: %w", err)
+		}
+		inferrer.Add(tree)
+	}
+
+	s := inferrer.Schema()
+
+	if err := sc.saveSchema(s); err != nil {
+		return err
+	}
+
+	return sc.saveGo(s)
+}
+
+// parseArgs validates the subcommand and splits the arguments into files and flags.
+func (sc *SchemaCommand) parseArgs(args []string) ([]string, error) {
+	if len(args) == 0 || args[0] != "infer" {
+		return nil, fmt.Errorf("unknown or missing schema subcommand (expected: schema infer <files...>)")
+	}
+
+	// Files come first, followed by the flags
+	args = args[1:]
+	split := len(args)
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			split = i
+			break
+		}
+	}
+
+	paths := args[:split]
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("not enough arguments (no files specified)")
+	}
+
+	if err := sc.flagset.Parse(args[split:]); err != nil {
+		return nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	return paths, nil
+}
+
+// saveSchema writes the schema as JSON to --out, or to stdout.
+func (sc *SchemaCommand) saveSchema(s *schema.Schema) error {
+	if sc.schemaPath == "" {
+		if sc.goPath != "" {
+			return nil
+		}
+		enc := json.NewEncoder(sc.out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "\t")
+		return enc.Encode(s)
+	}
+
+	if err := utils.SaveJSON(s, sc.schemaPath); err != nil {
+		return fmt.Errorf("failed to save schema: %w", err)
+	}
+	log.Println("Saved schema to", sc.schemaPath)
+	return nil
+}
+
+// saveGo writes the generated Go types to --go, if requested.
+func (sc *SchemaCommand) saveGo(s *schema.Schema) error {
+	if sc.goPath == "" {
+		return nil
+	}
+
+	pkg := sc.goPackage
+	if pkg == "" {
+		absPath, err := filepath.Abs(sc.goPath)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path: %w", err)
+		}
+		pkg = filepath.Base(filepath.Dir(absPath))
+	}
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("invalid Go package name %q, set one with --package", pkg)
+	}
+
+	src, err := schema.GenerateGo(s, schema.GoOptions{Package: pkg, TypeName: sc.goType})
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(sc.goPath, src, 0644); err != nil {
+		return fmt.Errorf("failed to save Go types: %w", err)
+	}
+	log.Println("Saved Go types to", sc.goPath)
+	return nil
+}
@@ -0,0 +1,28 @@
+// Package testutil holds helpers shared by the tests of several packages.
+package testutil
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+)
+
+// ParseString parses content as a mod file named test.txt, read from memory.
+func ParseString(t testing.TB, content string) *ast.AST {
+	t.Helper()
+
+	fsys := fstest.MapFS{"test.txt": {Data: []byte(content)}}
+	file, err := files.NewParadoxFSFile(fsys, "test.txt", "test.txt", files.Mod)
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+
+	tree, _, err := parser.ParseParadoxFile(file)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+	return tree
+}
@@ -22,10 +22,15 @@ func ReadFileWithUTF8BOM(filePath string) ([]byte, error) {
 		return nil, fmt.Errorf("could not read file: %w", err)
 	}
 
-	if hasUTF8BOM(content) {
-		return content[bomSize:], nil
+	return TrimUTF8BOM(content), nil
+}
+
+// TrimUTF8BOM removes the UTF-8 BOM at the start of data, if present.
+func TrimUTF8BOM(data []byte) []byte {
+	if hasUTF8BOM(data) {
+		return data[bomSize:]
 	}
-	return content, nil
+	return data
 }
 
 // hasUTF8BOM checks if the provided data begins with the UTF-8 BOM bytes.
@@ -1,8 +1,12 @@
 package ast
 
+import "github.com/unLomTrois/gock3/pkg/tokens"
+
 // AST represents the abstract syntax tree for a parsed Paradox file.
 type AST struct {
 	Filename string     `json:"filename"`
 	Fullpath string     `json:"fullpath"`
 	Block    *FileBlock `json:"data"`
+	// Comments holds the comments of the file in order, see the suppress package
+	Comments []*tokens.Token `json:"-"`
 }
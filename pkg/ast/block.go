@@ -24,6 +24,8 @@ type FileBlock = FieldBlock
 type FieldBlock struct {
 	Values []*Field   `json:"fields"`
 	Loc    tokens.Loc `json:"-"`
+	// End is the location of the closing brace, zero for the file block
+	End tokens.Loc `json:"-"`
 }
 
 func (fb *FieldBlock) IsBlock()        {}
@@ -123,6 +125,8 @@ func (fb *FieldBlock) GetTokenBlock(key string) *TokenBlock {
 type TokenBlock struct {
 	Values []*tokens.Token `json:"tokens"`
 	Loc    tokens.Loc      `json:"-"` // Added Loc field for location tracking
+	// End is the location of the closing brace
+	End tokens.Loc `json:"-"`
 }
 
 func (tb *TokenBlock) IsBlock()        {}
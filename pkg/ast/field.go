@@ -4,7 +4,10 @@ import "github.com/unLomTrois/gock3/pkg/tokens"
 
 // Field represents a single key-operator-value triple in the AST.
 type Field struct {
-	Key      *tokens.Token `json:"key"`
+	Key *tokens.Token `json:"key"`
+	// Operator is never nil in parsed ASTs, as the parser drops fields without one, but
+	// may be in ASTs built by hand: code walking an AST reads a nil operator as "=",
+	// like FormatField.
 	Operator *tokens.Token `json:"operator"`
 	Value    BlockOrValue  `json:"value"`
 }
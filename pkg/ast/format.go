@@ -0,0 +1,74 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// FormatValue renders a value as compact, single-line PDX script.
+func FormatValue(value BlockOrValue) string {
+	var sb strings.Builder
+	writeValue(&sb, value)
+	return sb.String()
+}
+
+// FormatField renders a field as compact, single-line PDX script.
+func FormatField(field *Field) string {
+	var sb strings.Builder
+	writeField(&sb, field)
+	return sb.String()
+}
+
+func writeField(sb *strings.Builder, field *Field) {
+	writeToken(sb, field.Key)
+	sb.WriteByte(' ')
+	if field.Operator != nil {
+		sb.WriteString(field.Operator.Value)
+	} else {
+		sb.WriteByte('=')
+	}
+	sb.WriteByte(' ')
+	writeValue(sb, field.Value)
+}
+
+func writeValue(sb *strings.Builder, value BlockOrValue) {
+	switch v := value.(type) {
+	case *tokens.Token:
+		writeToken(sb, v)
+	case *FieldBlock:
+		if v == nil || len(v.Values) == 0 {
+			sb.WriteString("{ }")
+			return
+		}
+		sb.WriteString("{ ")
+		for _, field := range v.Values {
+			writeField(sb, field)
+			sb.WriteByte(' ')
+		}
+		sb.WriteByte('}')
+	case *TokenBlock:
+		if v == nil || len(v.Values) == 0 {
+			sb.WriteString("{ }")
+			return
+		}
+		sb.WriteString("{ ")
+		for _, token := range v.Values {
+			writeToken(sb, token)
+			sb.WriteByte(' ')
+		}
+		sb.WriteByte('}')
+	}
+}
+
+func writeToken(sb *strings.Builder, token *tokens.Token) {
+	if token == nil {
+		return
+	}
+	if token.Type == tokens.QUOTED_STRING {
+		sb.WriteString(strconv.Quote(token.Value))
+		return
+	}
+	sb.WriteString(token.Value)
+}
@@ -0,0 +1,129 @@
+package ast
+
+// KeyPolicy describes how the game treats a key that appears more than once in the same block.
+type KeyPolicy uint8
+
+const (
+	// FirstWins keeps the first occurrence and ignores the rest.
+	FirstWins KeyPolicy = iota
+	// LastWins keeps the last occurrence, each one overriding the previous.
+	LastWins
+	// List keeps every occurrence, e.g. multiple "trait = x" or "option = { ... }".
+	List
+	// Merge combines the fields of every occurrence into a single block.
+	Merge
+)
+
+func (kp KeyPolicy) String() string {
+	switch kp {
+	case FirstWins:
+		return "first-wins"
+	case LastWins:
+		return "last-wins"
+	case List:
+		return "list"
+	case Merge:
+		return "merge"
+	default:
+		return "unknown"
+	}
+}
+
+func (kp KeyPolicy) MarshalText() ([]byte, error) {
+	return []byte(kp.String()), nil
+}
+
+// SingleValued reports whether only one occurrence of the key takes effect.
+func (kp KeyPolicy) SingleValued() bool {
+	return kp == FirstWins || kp == LastWins
+}
+
+// Policies declares the duplicate-key semantics of the keys in one kind of block,
+// and of the blocks nested under them.
+// A nil *Policies behaves like FieldBlock.GetField: every key is FirstWins.
+type Policies struct {
+	// Default applies to keys missing from Keys.
+	Default KeyPolicy
+	// Keys holds the policies of specific keys.
+	Keys map[string]KeyPolicy
+	// Blocks holds the policies of the blocks under specific keys.
+	Blocks map[string]*Policies
+	// DefaultBlock applies to blocks under keys missing from Blocks,
+	// such as the entries of a file keyed by their ids.
+	DefaultBlock *Policies
+}
+
+// Of returns the policy of the given key.
+func (p *Policies) Of(key string) KeyPolicy {
+	if p == nil {
+		return FirstWins
+	}
+	if policy, ok := p.Keys[key]; ok {
+		return policy
+	}
+	return p.Default
+}
+
+// Block returns the policies of the block under the given key.
+func (p *Policies) Block(key string) *Policies {
+	if p == nil {
+		return nil
+	}
+	if block, ok := p.Blocks[key]; ok {
+		return block
+	}
+	return p.DefaultBlock
+}
+
+// Resolve returns the fields with the given key as the game sees them under policy:
+// a single field for FirstWins, LastWins and Merge, and every field for List.
+// Merging combines the fields of all block values into one block; if any of
+// the values is not a field block, the last occurrence wins instead.
+func (fb *FieldBlock) Resolve(key string, policy KeyPolicy) []*Field {
+	fields := fb.GetFields(key)
+	if len(fields) <= 1 {
+		return fields
+	}
+
+	switch policy {
+	case LastWins:
+		return fields[len(fields)-1:]
+	case List:
+		return fields
+	case Merge:
+		return []*Field{mergeFields(fields)}
+	default:
+		return fields[:1]
+	}
+}
+
+// Lookup returns the effective field with the given key under the policies of this block,
+// or the first one if the key holds a list. It returns nil if the key is absent.
+func (fb *FieldBlock) Lookup(key string, policies *Policies) *Field {
+	fields := fb.Resolve(key, policies.Of(key))
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields[0]
+}
+
+// mergeFields combines repeated block fields into a single field located at the first one.
+func mergeFields(fields []*Field) *Field {
+	merged := &FieldBlock{Values: []*Field{}}
+	for i, field := range fields {
+		block, ok := field.Value.(*FieldBlock)
+		if !ok {
+			return fields[len(fields)-1]
+		}
+		if i == 0 {
+			merged.Loc = block.Loc
+		}
+		merged.Values = append(merged.Values, block.Values...)
+	}
+
+	return &Field{
+		Key:      fields[0].Key,
+		Operator: fields[0].Operator,
+		Value:    merged,
+	}
+}
@@ -0,0 +1,84 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+func field(key string, value BlockOrValue) *Field {
+	return &Field{
+		Key:      &tokens.Token{Value: key, Type: tokens.WORD},
+		Operator: &tokens.Token{Value: "=", Type: tokens.EQUALS},
+		Value:    value,
+	}
+}
+
+func word(value string) *tokens.Token {
+	return &tokens.Token{Value: value, Type: tokens.WORD}
+}
+
+func TestFieldBlock_Resolve(t *testing.T) {
+	block := &FieldBlock{
+		Values: []*Field{
+			field("trait", word("brave")),
+			field("trigger", &FieldBlock{Values: []*Field{field("a", word("yes"))}}),
+			field("trait", word("just")),
+			field("trigger", &FieldBlock{Values: []*Field{field("b", word("yes"))}}),
+			field("name", word("Teresa")),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		key    string
+		policy KeyPolicy
+		want   []string
+	}{
+		{name: "first wins", key: "trait", policy: FirstWins, want: []string{"brave"}},
+		{name: "last wins", key: "trait", policy: LastWins, want: []string{"just"}},
+		{name: "list", key: "trait", policy: List, want: []string{"brave", "just"}},
+		{name: "merge", key: "trigger", policy: Merge, want: []string{"{ a = yes b = yes }"}},
+		{name: "single occurrence", key: "name", policy: List, want: []string{"Teresa"}},
+		{name: "missing key", key: "culture", policy: LastWins, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := block.Resolve(tt.key, tt.policy)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Resolve() returned %d fields, want %d", len(got), len(tt.want))
+			}
+			for i, f := range got {
+				if text := FormatValue(f.Value); text != tt.want[i] {
+					t.Errorf("Resolve()[%d] = %q, want %q", i, text, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPolicies_Lookup(t *testing.T) {
+	policies := &Policies{
+		Default: List,
+		Keys:    map[string]KeyPolicy{"culture": LastWins},
+		DefaultBlock: &Policies{
+			Default: FirstWins,
+		},
+	}
+	block := &FieldBlock{
+		Values: []*Field{
+			field("culture", word("castilian")),
+			field("culture", word("leonese")),
+		},
+	}
+
+	if got := block.Lookup("culture", policies); FormatValue(got.Value) != "leonese" {
+		t.Errorf("Lookup(culture) = %q, want %q", FormatValue(got.Value), "leonese")
+	}
+	if got := block.Lookup("culture", nil); FormatValue(got.Value) != "castilian" {
+		t.Errorf("Lookup(culture) with nil policies = %q, want %q", FormatValue(got.Value), "castilian")
+	}
+	if got := policies.Block("70027").Of("anything"); got != FirstWins {
+		t.Errorf("DefaultBlock policy = %v, want %v", got, FirstWins)
+	}
+}
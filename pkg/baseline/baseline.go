@@ -0,0 +1,263 @@
+// Package baseline records the diagnostics a mod already has, so that only new ones
+// are reported. Diagnostics are keyed by code, file and a fingerprint of their place in
+// the AST rather than by line, so a baseline survives unrelated edits of the file.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// Version is the version of the baseline file format.
+const Version = 1
+
+// Entry is a known diagnostic. Count is the number of diagnostics sharing the key,
+// such as the same mistake repeated in identical blocks.
+type Entry struct {
+	Code        codes.Code `json:"code"`
+	File        string     `json:"file"`
+	Fingerprint string     `json:"fingerprint"`
+	Count       int        `json:"count"`
+}
+
+type key struct {
+	code        codes.Code
+	file        string
+	fingerprint string
+}
+
+// Baseline is a set of known diagnostics.
+type Baseline struct {
+	root   string
+	counts map[key]int
+}
+
+type baselineFile struct {
+	Version     int      `json:"version"`
+	Diagnostics []*Entry `json:"diagnostics"`
+}
+
+// New creates an empty baseline. Files are recorded relative to root.
+func New(root string) *Baseline {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return &Baseline{root: root, counts: make(map[key]int)}
+}
+
+// Add records the diagnostics of a parsed file.
+func (b *Baseline) Add(tree *ast.AST, diagnostics []*report.DiagnosticItem) {
+	for _, diag := range diagnostics {
+		b.counts[b.keyOf(tree, diag)]++
+	}
+}
+
+// Filter returns the diagnostics of a parsed file that are not in the baseline. Each
+// entry hides at most Count diagnostics, so repeating a known mistake is reported.
+func (b *Baseline) Filter(tree *ast.AST, diagnostics []*report.DiagnosticItem) []*report.DiagnosticItem {
+	kept := make([]*report.DiagnosticItem, 0, len(diagnostics))
+	for _, diag := range diagnostics {
+		k := b.keyOf(tree, diag)
+		if b.counts[k] > 0 {
+			b.counts[k]--
+			continue
+		}
+		kept = append(kept, diag)
+	}
+	return kept
+}
+
+// Entries returns the entries of the baseline, sorted by file, code and fingerprint.
+func (b *Baseline) Entries() []*Entry {
+	entries := make([]*Entry, 0, len(b.counts))
+	for k, count := range b.counts {
+		if count > 0 {
+			entries = append(entries, &Entry{Code: k.code, File: k.file, Fingerprint: k.fingerprint, Count: count})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+	return entries
+}
+
+// Write writes the baseline as JSON, sorted so that it diffs well under version control.
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	return enc.Encode(&baselineFile{Version: Version, Diagnostics: b.Entries()})
+}
+
+// Save writes the baseline to a file.
+func (b *Baseline) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating baseline: %w", err)
+	}
+	if err := b.Write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing baseline: %w", err)
+	}
+	return f.Close()
+}
+
+// Read reads a baseline written by Write. Files are resolved relative to root.
+func Read(r io.Reader, root string) (*Baseline, error) {
+	var file baselineFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("decoding baseline: %w", err)
+	}
+	if file.Version != Version {
+		return nil, fmt.Errorf("unsupported baseline version %d, expected %d", file.Version, Version)
+	}
+
+	b := New(root)
+	for _, entry := range file.Diagnostics {
+		b.counts[key{code: entry.Code, file: entry.File, fingerprint: entry.Fingerprint}] += entry.Count
+	}
+	return b, nil
+}
+
+// Load reads a baseline file.
+func Load(path, root string) (*Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening baseline: %w", err)
+	}
+	defer f.Close()
+
+	b, err := Read(f, root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+func (b *Baseline) keyOf(tree *ast.AST, diag *report.DiagnosticItem) key {
+	return key{
+		code:        diag.Code,
+		file:        b.relative(diag.Pointer.Loc),
+		fingerprint: Fingerprint(tree, diag),
+	}
+}
+
+// relative returns the slash-separated path of a file relative to the root, or its
+// full path if it lies outside of it.
+func (b *Baseline) relative(loc tokens.Loc) string {
+	path, err := loc.GetIdx().Fullpath()
+	if err != nil {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	rel, err := filepath.Rel(b.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Fingerprint identifies a diagnostic independently of its line: it hashes the code,
+// the keys of the fields enclosing the diagnostic and the text of the token it points
+// at. Moving a block or editing the lines around it keeps the fingerprint.
+func Fingerprint(tree *ast.AST, diag *report.DiagnosticItem) string {
+	var path []string
+	var text string
+	if tree != nil && tree.Block != nil {
+		path, text = locate(tree.Block, diag.Pointer.Loc)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", diag.Code, strings.Join(path, "/"), text)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// locate returns the keys of the fields enclosing a location, and the value of the
+// token at the location if there is one.
+func locate(block *ast.FieldBlock, loc tokens.Loc) ([]string, string) {
+	for _, field := range block.Values {
+		if !field.Key.Loc.SameFile(loc) || !contains(field, loc) {
+			continue
+		}
+		path := []string{field.Key.Value}
+
+		switch value := field.Value.(type) {
+		case *ast.FieldBlock:
+			if at(field.Key, loc) {
+				return path, field.Key.Value
+			}
+			nested, text := locate(value, loc)
+			return append(path, nested...), text
+		case *ast.TokenBlock:
+			for _, token := range value.Values {
+				if at(token, loc) {
+					return path, token.Value
+				}
+			}
+		case *tokens.Token:
+			if at(value, loc) {
+				return path, value.Value
+			}
+		}
+		for _, token := range []*tokens.Token{field.Key, field.Operator} {
+			if at(token, loc) {
+				return path, token.Value
+			}
+		}
+		return path, ""
+	}
+	return nil, ""
+}
+
+// contains reports whether a location lies between the key of a field and the end of its value.
+func contains(field *ast.Field, loc tokens.Loc) bool {
+	if before(loc, field.Key.Loc) {
+		return false
+	}
+
+	var end tokens.Loc
+	switch value := field.Value.(type) {
+	case *ast.FieldBlock:
+		end = value.End
+	case *ast.TokenBlock:
+		end = value.End
+	case *tokens.Token:
+		end = value.Loc
+	default:
+		end = field.Key.Loc
+		if field.Operator != nil {
+			end = field.Operator.Loc
+		}
+	}
+	// Unclosed blocks extend to the end of the file
+	return end.Line == 0 || !before(end, loc)
+}
+
+func at(token *tokens.Token, loc tokens.Loc) bool {
+	return token != nil && token.Loc.Line == loc.Line && token.Loc.Column == loc.Column
+}
+
+func before(a, b tokens.Loc) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+}
@@ -0,0 +1,154 @@
+package baseline_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/baseline"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/validator"
+)
+
+// check writes content to name under root and returns its parse tree and diagnostics,
+// including repeated keys.
+func check(t *testing.T, root, name, content string) (*ast.AST, []*report.DiagnosticItem) {
+	t.Helper()
+
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tree, diagnostics, err := parser.ParseParadoxFile(files.NewParadoxTxtFile(path, files.Mod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree, append(diagnostics, validator.DuplicateKeys(tree.Block, validator.CharacterHistory)...)
+}
+
+func TestBaseline(t *testing.T) {
+	const original = "70027 = {\n\tname = Eric\n\tname = Erik\n\tcost = 0.12345\n}\n"
+
+	tests := []struct {
+		name    string
+		content string
+		// want is the number of new diagnostics
+		want int
+	}{
+		{
+			name:    "unchanged",
+			content: original,
+			want:    0,
+		},
+		{
+			name:    "moved by unrelated edits",
+			content: "# characters\n70001 = {\n\tname = Ragnar\n}\n\n70027 = {\n\tdynasty = 1\n\n\tname = Eric\n\tname = Erik\n\tcost    =    0.12345\n}\n",
+			want:    0,
+		},
+		{
+			name:    "repeated mistake",
+			content: original + "70028 = {\n\tname = Eric\n\tname = Erik\n}\n",
+			want:    1,
+		},
+		{
+			name:    "same mistake in another entry",
+			content: "70027 = {\n\tname = Eric\n\tcost = 0.12345\n}\n70028 = {\n\tname = Eric\n\tname = Erik\n}\n",
+			want:    1,
+		},
+		{
+			name:    "changed value",
+			content: "70027 = {\n\tname = Eric\n\tname = Erik\n\tcost = 0.54321\n}\n",
+			want:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			name := filepath.Join("history", "characters", "test.txt")
+
+			tree, diagnostics := check(t, root, name, original)
+			if len(diagnostics) != 2 {
+				t.Fatalf("got %d diagnostics in the original, want 2", len(diagnostics))
+			}
+			b := baseline.New(root)
+			b.Add(tree, diagnostics)
+
+			// Round trip through the file format
+			var buf bytes.Buffer
+			if err := b.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := baseline.Read(&buf, root)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tree, diagnostics = check(t, root, name, tt.content)
+			if got := loaded.Filter(tree, diagnostics); len(got) != tt.want {
+				t.Errorf("got %d new diagnostics, want %d", len(got), tt.want)
+				for _, diag := range got {
+					t.Log(diag.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestBaseline_Entries(t *testing.T) {
+	root := t.TempDir()
+	tree, diagnostics := check(t, root, "test.txt", "a = {\n\tname = x\n\tname = x\n\tname = x\n}\n")
+
+	b := baseline.New(root)
+	b.Add(tree, diagnostics)
+	entries := b.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if e := entries[0]; e.File != "test.txt" || e.Code != "V0001" || e.Count != 2 || len(e.Fingerprint) != 16 {
+		t.Errorf("entry = %+v, want V0001 in test.txt with count 2", e)
+	}
+}
+
+func TestRead_Version(t *testing.T) {
+	if _, err := baseline.Read(bytes.NewBufferString(`{"version": 99, "diagnostics": []}`), "."); err == nil {
+		t.Errorf("expected an error for an unsupported version")
+	}
+}
+
+func TestFingerprint_BlockKey(t *testing.T) {
+	tree, _ := check(t, t.TempDir(), "test.txt", "a = {\n\tb = {\n\t\tx = 1\n\t}\n}\n")
+	block := tree.Block.Values[0].Value.(*ast.FieldBlock).Values[0]
+
+	// On the key of the block, and on its closing brace
+	onKey := report.FromToken(block.Key, codes.RepeatedKey, "repeated")
+	onEnd := report.FromLoc(block.Value.(*ast.FieldBlock).End, codes.RepeatedKey, "repeated")
+	if baseline.Fingerprint(tree, onKey) == baseline.Fingerprint(tree, onEnd) {
+		t.Errorf("a diagnostic on a block key has the fingerprint of one inside the block")
+	}
+}
+
+func TestFingerprint_MissingOperator(t *testing.T) {
+	tree, _ := check(t, t.TempDir(), "test.txt", "a = b\n")
+	field := tree.Block.Values[0]
+	diag := report.FromToken(field.Key, codes.RepeatedKey, "repeated")
+	want := baseline.Fingerprint(tree, diag)
+
+	// Built by hand, see ast.Field
+	field.Operator = nil
+	if got := baseline.Fingerprint(tree, diag); got != want {
+		t.Errorf("Fingerprint() = %s without the operator, want %s", got, want)
+	}
+	field.Value = nil
+	if got := baseline.Fingerprint(tree, diag); got != want {
+		t.Errorf("Fingerprint() = %s without the operator and value, want %s", got, want)
+	}
+}
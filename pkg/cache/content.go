@@ -0,0 +1,198 @@
+// Package cache keeps the content of the files read by the parser, for the reporters
+// to show their lines without reading them again.
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// DefaultMaxBytes is the memory cap of a ContentCache created with a cap of 0 or less.
+const DefaultMaxBytes = 64 << 20
+
+// ErrLineOutOfRange is returned for a location past the last line of its file.
+var ErrLineOutOfRange = errors.New("line out of range")
+
+// ContentCache holds the content of files by their path table index, up to a memory
+// cap: the least recently used files are dropped first. A file is read again when its
+// modification time or size changes, and keeps its entry if its content didn't. It is
+// safe for concurrent use, and can be set as the reader of a files.PathTable.
+type ContentCache struct {
+	maxBytes int64
+
+	mu   sync.Mutex
+	size int64
+	// order holds the entries, the most recently used first
+	order   *list.List
+	entries map[files.PathTableIndex]*list.Element
+}
+
+// entry is the content of a file with what it was read at.
+type entry struct {
+	idx     files.PathTableIndex
+	content []byte
+	hash    uint64
+	modTime time.Time
+	size    int64
+	// lines are split on demand, they share the memory of text
+	text  string
+	lines []string
+}
+
+func (e *entry) bytes() int64 {
+	return int64(len(e.content) + len(e.text))
+}
+
+// NewContentCache creates a ContentCache holding at most maxBytes of content, or
+// DefaultMaxBytes if maxBytes is 0 or less. A file larger than the cap is only kept
+// until another file is read.
+func NewContentCache(maxBytes int64) *ContentCache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	return &ContentCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[files.PathTableIndex]*list.Element),
+	}
+}
+
+// ReadFile returns the content of a file, reading it if it isn't cached or changed
+// since. The content is shared and must not be modified.
+func (c *ContentCache) ReadFile(idx files.PathTableIndex) ([]byte, error) {
+	e, err := c.load(idx)
+	if err != nil {
+		return nil, err
+	}
+	return e.content, nil
+}
+
+// Lines returns the lines of a file, without the byte order mark and with CRLF line
+// endings read as LF, as the lexer does.
+func (c *ContentCache) Lines(idx files.PathTableIndex) ([]string, error) {
+	e, err := c.load(idx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.lines == nil {
+		e.text = strings.ReplaceAll(strings.TrimPrefix(string(e.content), "\ufeff"), "\r\n", "\n")
+		e.lines = strings.Split(e.text, "\n")
+		if elem, ok := c.entries[idx]; ok && elem.Value == e {
+			c.size += int64(len(e.text))
+			c.evict()
+		}
+	}
+	return e.lines, nil
+}
+
+// Line returns the line of a location, or an error wrapping ErrLineOutOfRange if the
+// file has no such line.
+func (c *ContentCache) Line(loc *tokens.Loc) (string, error) {
+	lines, err := c.Lines(loc.GetIdx())
+	if err != nil {
+		return "", err
+	}
+	if loc.Line < 1 || int(loc.Line) > len(lines) {
+		return "", fmt.Errorf("%w: line %d of %d", ErrLineOutOfRange, loc.Line, len(lines))
+	}
+	return lines[loc.Line-1], nil
+}
+
+// Invalidate drops a file from the cache, it is read again on the next use.
+func (c *ContentCache) Invalidate(idx files.PathTableIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[idx]; ok {
+		c.remove(elem)
+	}
+}
+
+// Len returns the number of cached files.
+func (c *ContentCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Size returns the memory held by the cached files, in bytes.
+func (c *ContentCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// load returns the entry of a file, fresh as of its modification time and size. Files
+// are read without holding the lock, for the parser to read them in parallel.
+func (c *ContentCache) load(idx files.PathTableIndex) (*entry, error) {
+	info, err := idx.Stat()
+	if err != nil {
+		c.Invalidate(idx)
+		return nil, err
+	}
+
+	c.mu.Lock()
+	var cached *entry
+	if elem, ok := c.entries[idx]; ok {
+		cached = elem.Value.(*entry)
+		if cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			c.order.MoveToFront(elem)
+			c.mu.Unlock()
+			return cached, nil
+		}
+	}
+	c.mu.Unlock()
+
+	content, err := idx.ReadFile()
+	if err != nil {
+		c.Invalidate(idx)
+		return nil, err
+	}
+	hash := fnv.New64a()
+	hash.Write(content)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Touched but not modified
+	if cached != nil && cached.hash == hash.Sum64() {
+		if elem, ok := c.entries[idx]; ok && elem.Value == cached {
+			cached.modTime, cached.size = info.ModTime(), info.Size()
+			c.order.MoveToFront(elem)
+			return cached, nil
+		}
+	}
+
+	if elem, ok := c.entries[idx]; ok {
+		c.remove(elem)
+	}
+	e := &entry{idx: idx, content: content, hash: hash.Sum64(), modTime: info.ModTime(), size: info.Size()}
+	c.entries[idx] = c.order.PushFront(e)
+	c.size += e.bytes()
+	c.evict()
+	return e, nil
+}
+
+// evict drops the least recently used files until the cache fits its cap, keeping the
+// most recent one.
+func (c *ContentCache) evict() {
+	for c.size > c.maxBytes && c.order.Len() > 1 {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *ContentCache) remove(elem *list.Element) {
+	e := c.order.Remove(elem).(*entry)
+	delete(c.entries, e.idx)
+	c.size -= e.bytes()
+}
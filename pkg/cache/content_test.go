@@ -0,0 +1,171 @@
+package cache_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/unLomTrois/gock3/pkg/cache"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// storeFile stores a file of fsys in a new path table and returns its index.
+func storeFile(t *testing.T, fsys fs.FS, name string) files.PathTableIndex {
+	t.Helper()
+	file, err := files.NewParadoxFSFile(fsys, name, name, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *files.NewPathTable().Add(file).StoreInPathTable()
+}
+
+func TestContentCache_Invalidation(t *testing.T) {
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{"00_traits.txt": {Data: []byte("brave = yes\n"), ModTime: modTime}}
+	idx := storeFile(t, fsys, "00_traits.txt")
+
+	c := cache.NewContentCache(0)
+	lines, err := c.Lines(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same modification time and size: the cached content is used
+	fsys["00_traits.txt"].Data = []byte("brave = no \n")
+	if got, _ := c.ReadFile(idx); string(got) != "brave = yes\n" {
+		t.Errorf("ReadFile() = %q, want the cached content", got)
+	}
+
+	// Touched but not modified: the entry is kept with its lines
+	fsys["00_traits.txt"].Data = []byte("brave = yes\n")
+	fsys["00_traits.txt"].ModTime = modTime.Add(time.Hour)
+	again, err := c.Lines(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &again[0] != &lines[0] {
+		t.Errorf("lines of an unmodified file were split again")
+	}
+
+	// Modified
+	fsys["00_traits.txt"].Data = []byte("brave = no\r\nshy = yes\n")
+	fsys["00_traits.txt"].ModTime = modTime.Add(2 * time.Hour)
+	lines, err = c.Lines(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 || lines[0] != "brave = no" || lines[1] != "shy = yes" {
+		t.Errorf("Lines() = %q, want the new content", lines)
+	}
+
+	// Removed
+	delete(fsys, "00_traits.txt")
+	if _, err := c.ReadFile(idx); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile() error = %v, want fs.ErrNotExist", err)
+	}
+	if c.Len() != 0 || c.Size() != 0 {
+		t.Errorf("removed file still cached: %d file(s), %d bytes", c.Len(), c.Size())
+	}
+}
+
+func TestContentCache_Eviction(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt": {Data: []byte("aaaa")},
+		"b.txt": {Data: []byte("bbbb")},
+		"c.txt": {Data: []byte("cccc")},
+	}
+	pt := files.NewPathTable()
+	index := func(name string) files.PathTableIndex {
+		file, err := files.NewParadoxFSFile(fsys, name, name, files.Mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *pt.Add(file).StoreInPathTable()
+	}
+	a, b, c := index("a.txt"), index("b.txt"), index("c.txt")
+
+	content := cache.NewContentCache(8)
+	for _, idx := range []files.PathTableIndex{a, b, a, c} {
+		if _, err := content.ReadFile(idx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// b is the least recently used
+	if content.Len() != 2 || content.Size() != 8 {
+		t.Errorf("cache holds %d file(s), %d bytes, want 2 files, 8 bytes", content.Len(), content.Size())
+	}
+
+	fsys["a.txt"].Data = []byte("AAAA")
+	fsys["b.txt"].Data = []byte("BBBB")
+	if got, _ := content.ReadFile(a); string(got) != "aaaa" {
+		t.Errorf("a.txt = %q, want the cached content", got)
+	}
+	if got, _ := content.ReadFile(b); string(got) != "BBBB" {
+		t.Errorf("b.txt = %q, want the content read again", got)
+	}
+
+	// Splitting lines counts too
+	if _, err := content.Lines(b); err != nil {
+		t.Fatal(err)
+	}
+	if content.Len() != 1 || content.Size() != 8 {
+		t.Errorf("cache holds %d file(s), %d bytes, want 1 file, 8 bytes", content.Len(), content.Size())
+	}
+}
+
+func TestContentCache_Line(t *testing.T) {
+	fsys := fstest.MapFS{"00_traits.txt": {Data: []byte("\ufeffbrave = {\n}\n")}}
+	file, err := files.NewParadoxFSFile(fsys, "00_traits.txt", "00_traits.txt", files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := tokens.LocFromParadoxFile(file)
+
+	c := cache.NewContentCache(0)
+	if line, err := c.Line(loc); err != nil || line != "brave = {" {
+		t.Errorf("Line(1) = %q, %v", line, err)
+	}
+	loc.Line = 4
+	if _, err := c.Line(loc); !errors.Is(err, cache.ErrLineOutOfRange) {
+		t.Errorf("Line(4) error = %v, want ErrLineOutOfRange", err)
+	}
+	loc.Line = 0
+	if _, err := c.Line(loc); !errors.Is(err, cache.ErrLineOutOfRange) {
+		t.Errorf("Line(0) error = %v, want ErrLineOutOfRange", err)
+	}
+
+	if _, err := c.Lines(files.PathTableIndex{}); !errors.Is(err, files.ErrUnknownPath) {
+		t.Errorf("Lines() of the zero index error = %v, want ErrUnknownPath", err)
+	}
+}
+
+func TestPathTable_SetReader(t *testing.T) {
+	fsys := fstest.MapFS{"00_traits.txt": {Data: []byte("brave = yes\n")}}
+	pt := files.NewPathTable()
+	c := cache.NewContentCache(0)
+	pt.SetReader(c)
+
+	file, err := files.NewParadoxFSFile(fsys, "00_traits.txt", "00_traits.txt", files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt.Add(file)
+	if _, err := file.ReadFile(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Len() != 1 {
+		t.Errorf("the file wasn't read through the cache")
+	}
+
+	// Files outside of the table are read directly
+	other, err := files.NewParadoxFSFile(fsys, "00_traits.txt", "00_traits.txt", files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.ReadFile(); err != nil || c.Len() != 1 {
+		t.Errorf("a standalone file was read through the cache: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+// Package config reads the gock3 configuration of a mod, a JSON file at its root:
+//
+//	{
+//		"severity": {
+//			"P0010": "error",
+//			"V0001": "off"
+//		}
+//	}
+//
+// Severity overrides the default severity of diagnostic codes, "off" disables a code.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// Filename is the name of the configuration file looked up in the mod root.
+const Filename = ".gock3.json"
+
+// Off disables a code in the severity overrides.
+const Off = "off"
+
+// Config is the configuration of a mod.
+type Config struct {
+	// Severity maps codes to their severity, or to Off.
+	Severity map[codes.Code]string `json:"severity"`
+
+	overrides map[codes.Code]severity.Severity
+	disabled  map[codes.Code]bool
+}
+
+// Default returns the configuration used when a mod has none.
+func Default() *Config {
+	return &Config{}
+}
+
+// Load reads and validates a configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	c := &Config{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Discover loads the configuration file of the mod root, or returns the default
+// configuration if there is none.
+func Discover(root string) (*Config, error) {
+	c, err := Load(filepath.Join(root, Filename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Default(), nil
+	}
+	return c, err
+}
+
+// validate checks the codes and severities of the overrides.
+func (c *Config) validate() error {
+	c.overrides = make(map[codes.Code]severity.Severity)
+	c.disabled = make(map[codes.Code]bool)
+
+	for code, level := range c.Severity {
+		entry, ok := codes.Lookup(code)
+		if !ok {
+			return fmt.Errorf("unknown diagnostic code %q in severity", code)
+		}
+		if level == Off {
+			c.disabled[entry.Code] = true
+			continue
+		}
+		sev, err := severity.Parse(level)
+		if err != nil {
+			return fmt.Errorf("severity of %s: %w", entry.Code, err)
+		}
+		c.overrides[entry.Code] = sev
+	}
+	return nil
+}
+
+// Apply removes the diagnostics of disabled codes and overrides the severity of the others.
+func (c *Config) Apply(diagnostics []*report.DiagnosticItem) []*report.DiagnosticItem {
+	if len(c.overrides) == 0 && len(c.disabled) == 0 {
+		return diagnostics
+	}
+
+	kept := make([]*report.DiagnosticItem, 0, len(diagnostics))
+	for _, diag := range diagnostics {
+		if c.disabled[diag.Code] {
+			continue
+		}
+		if sev, ok := c.overrides[diag.Code]; ok {
+			diag.Severity = sev
+		}
+		kept = append(kept, diag)
+	}
+	return kept
+}
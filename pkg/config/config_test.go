@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/config"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, config.Filename), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestApply(t *testing.T) {
+	dir := writeConfig(t, `{"severity": {"p0010": "error", "V0001": "off"}}`)
+	cfg, err := config.Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics := cfg.Apply([]*report.DiagnosticItem{
+		report.NewDiagnosticItem(codes.NumberPrecisionLoss, "precision", &report.DiagnosticPointer{}),
+		report.NewDiagnosticItem(codes.RepeatedKey, "repeated", &report.DiagnosticPointer{}),
+		report.NewDiagnosticItem(codes.ExpectedField, "field", &report.DiagnosticPointer{}),
+	})
+
+	if len(diagnostics) != 2 {
+		t.Fatalf("got %d diagnostics, want the disabled one removed", len(diagnostics))
+	}
+	if diagnostics[0].Severity != severity.Error {
+		t.Errorf("P0010 severity = %v, want the override", diagnostics[0].Severity)
+	}
+	if diagnostics[1].Severity != codes.ExpectedField.Severity() {
+		t.Errorf("P0003 severity = %v, want the default", diagnostics[1].Severity)
+	}
+}
+
+func TestDiscover_Missing(t *testing.T) {
+	cfg, err := config.Discover(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	diag := report.NewDiagnosticItem(codes.RepeatedKey, "repeated", &report.DiagnosticPointer{})
+	if got := cfg.Apply([]*report.DiagnosticItem{diag}); len(got) != 1 || got[0].Severity != severity.Warning {
+		t.Errorf("the default configuration changed the diagnostics")
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown code":     `{"severity": {"X1234": "error"}}`,
+		"unknown severity": `{"severity": {"P0010": "fatal"}}`,
+		"invalid JSON":     `{"severity": `,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := config.Discover(writeConfig(t, content)); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
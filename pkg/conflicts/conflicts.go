@@ -0,0 +1,234 @@
+// Package conflicts finds what the mods of a load order override: files replacing the
+// files of the game and of the mods loaded before, and objects of the common folders,
+// such as traits, defined by several files, with the definition the game keeps.
+package conflicts
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// CommonFolder holds the databases whose objects are checked for overrides.
+const CommonFolder = "common"
+
+// Policies holds how the game treats an object defined by several files of a database
+// folder, e.g. "common/on_action". Folders missing from it are LastWins: the files are
+// loaded in the order of their names and the last definition replaces the others.
+var Policies = map[string]ast.KeyPolicy{
+	"common/on_action": ast.Merge,
+	"common/defines":   ast.Merge,
+}
+
+// PolicyOf returns the policy of the objects of a database folder.
+func PolicyOf(folder string) ast.KeyPolicy {
+	if policy, ok := Policies[folder]; ok {
+		return policy
+	}
+	return ast.LastWins
+}
+
+// Report lists the overrides of a load order.
+type Report struct {
+	Files   []*FileOverride   `json:"files"`
+	Objects []*ObjectOverride `json:"objects"`
+}
+
+// Source is a file loaded for a path, from the game or a mod.
+type Source struct {
+	// Root is the name of the game or mod the file comes from.
+	Root     string `json:"root"`
+	Fullpath string `json:"fullpath"`
+	// Identical tells whether a replaced file has the same content as the winner.
+	Identical bool `json:"identical,omitempty"`
+}
+
+// FileOverride is a path with several files, the last one loaded replacing the others.
+type FileOverride struct {
+	Path     string    `json:"path"`
+	Winner   *Source   `json:"winner"`
+	Replaced []*Source `json:"replaced"`
+}
+
+// Definition is a top-level entry of a database file defining an object.
+type Definition struct {
+	Root   string `json:"root"`
+	File   string `json:"file"`
+	Line   uint32 `json:"line"`
+	Column uint16 `json:"column"`
+
+	// Loc is the location of the key of the entry.
+	Loc tokens.Loc `json:"-"`
+}
+
+// ObjectOverride is an object defined by several files of a database folder.
+type ObjectOverride struct {
+	Folder string        `json:"folder"`
+	Key    string        `json:"key"`
+	Policy ast.KeyPolicy `json:"policy"`
+	// Winner is the definition the game keeps, nil if the definitions are merged.
+	Winner *Definition `json:"winner,omitempty"`
+	// Definitions are every definition of the object, in load order.
+	Definitions []*Definition `json:"definitions"`
+}
+
+// Find reports the overrides of the mods of a GameFS. The database files are parsed
+// with at most workers goroutines, see parser.ParseFiles, and stored in paths.
+func Find(gfs *files.GameFS, paths *files.PathTable, workers int) (*Report, error) {
+	modFiles, err := gfs.ModFiles(".")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Files: []*FileOverride{}, Objects: []*ObjectOverride{}}
+	folders := make(map[string]bool)
+	for i, entry := range modFiles {
+		if i > 0 && modFiles[i-1].Path == entry.Path {
+			continue
+		}
+		if isDatabaseFile(entry.Path) {
+			folders[path.Dir(entry.Path)] = true
+		}
+
+		override, err := fileOverride(gfs, entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		if override != nil {
+			report.Files = append(report.Files, override)
+		}
+	}
+
+	objects, err := objectOverrides(gfs, paths, folders, workers)
+	if err != nil {
+		return nil, err
+	}
+	report.Objects = objects
+	return report, nil
+}
+
+// fileOverride returns the override of a path, or nil if a single file is loaded for it.
+func fileOverride(gfs *files.GameFS, name string) (*FileOverride, error) {
+	stack, err := gfs.Stack(name)
+	if err != nil || len(stack) < 2 {
+		return nil, err
+	}
+
+	winner := stack[len(stack)-1]
+	content, err := winner.File().ReadFile()
+	if err != nil {
+		return nil, err
+	}
+
+	override := &FileOverride{Path: name, Winner: newSource(winner)}
+	for _, entry := range slices.Backward(stack[:len(stack)-1]) {
+		replaced, err := entry.File().ReadFile()
+		if err != nil {
+			return nil, err
+		}
+		source := newSource(entry)
+		source.Identical = bytes.Equal(replaced, content)
+		override.Replaced = append(override.Replaced, source)
+	}
+	return override, nil
+}
+
+func newSource(entry *files.Entry) *Source {
+	return &Source{Root: entry.Root().Name, Fullpath: entry.FullPath}
+}
+
+// objectOverrides parses the effective files of the database folders and returns the
+// objects defined by several files, one of them from a mod.
+func objectOverrides(gfs *files.GameFS, paths *files.PathTable, folders map[string]bool, workers int) ([]*ObjectOverride, error) {
+	entries, err := gfs.Files(CommonFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	var paradoxFiles []files.ParadoxFile
+	var loaded []*files.Entry
+	for _, entry := range entries {
+		if folders[path.Dir(entry.Path)] && isDatabaseFile(entry.Path) {
+			paradoxFiles = append(paradoxFiles, paths.Add(entry.File()))
+			loaded = append(loaded, entry)
+		}
+	}
+
+	// The files of a folder are sorted by name, in the order the game loads them
+	type object struct{ folder, key string }
+	definitions := make(map[object][]*Definition)
+	fromMod := make(map[object]bool)
+	var order []object
+	for i, result := range parser.ParseFiles(paradoxFiles, workers) {
+		if result.Err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", loaded[i].FullPath, result.Err)
+		}
+		if result.AST.Block == nil {
+			continue
+		}
+
+		folder := path.Dir(loaded[i].Path)
+		for _, field := range result.AST.Block.Values {
+			key := field.Key.Value
+			// Script values local to the file, such as "@cost = 100"
+			if strings.HasPrefix(key, "@") {
+				continue
+			}
+			obj := object{folder: folder, key: key}
+			if _, ok := definitions[obj]; !ok {
+				order = append(order, obj)
+			}
+			definitions[obj] = append(definitions[obj], &Definition{
+				Root:   loaded[i].Root().Name,
+				File:   loaded[i].FullPath,
+				Line:   field.Key.Loc.Line,
+				Column: field.Key.Loc.Column,
+				Loc:    field.Key.Loc,
+			})
+			fromMod[obj] = fromMod[obj] || loaded[i].Mod != nil
+		}
+	}
+
+	overrides := []*ObjectOverride{}
+	for _, obj := range order {
+		defs := definitions[obj]
+		if !fromMod[obj] || !definedInFiles(defs) {
+			continue
+		}
+		override := &ObjectOverride{Folder: obj.folder, Key: obj.key, Policy: PolicyOf(obj.folder), Definitions: defs}
+		switch override.Policy {
+		case ast.FirstWins:
+			override.Winner = defs[0]
+		case ast.LastWins:
+			override.Winner = defs[len(defs)-1]
+		}
+		overrides = append(overrides, override)
+	}
+	slices.SortStableFunc(overrides, func(a, b *ObjectOverride) int {
+		return strings.Compare(a.Folder, b.Folder)
+	})
+	return overrides, nil
+}
+
+// definedInFiles reports whether the definitions come from more than one file. Keys
+// repeated in a single file are left to the duplicates validator.
+func definedInFiles(defs []*Definition) bool {
+	for _, def := range defs[1:] {
+		if def.File != defs[0].File {
+			return true
+		}
+	}
+	return false
+}
+
+// isDatabaseFile reports whether a path is a script file of a common folder.
+func isDatabaseFile(name string) bool {
+	return strings.HasPrefix(name, CommonFolder+"/") && strings.EqualFold(path.Ext(name), files.ScriptExt)
+}
@@ -0,0 +1,131 @@
+package conflicts_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/conflicts"
+	"github.com/unLomTrois/gock3/pkg/files"
+)
+
+func writeFiles(t *testing.T, root string, contents map[string]string) {
+	t.Helper()
+	for name, content := range contents {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTestGameFS(t *testing.T) *files.GameFS {
+	t.Helper()
+	dir := t.TempDir()
+
+	vanilla := filepath.Join(dir, "game")
+	writeFiles(t, vanilla, map[string]string{
+		"common/traits/00_traits.txt":        "@cost = 10\nbrave = { }\ncraven = { }\n",
+		"common/on_action/00_on_actions.txt": "on_birth = { }\n",
+		"events/00_events.txt":               "namespace = test\n",
+		"gfx/icon.dds":                       "icon",
+	})
+
+	first := &files.ModRoot{Name: "first", Path: filepath.Join(dir, "first")}
+	writeFiles(t, first.Path, map[string]string{
+		"common/traits/10_traits.txt":        "@cost = 20\nbrave = { }\n",
+		"common/on_action/10_on_actions.txt": "on_birth = { }\n",
+		"events/00_events.txt":               "namespace = test\n",
+	})
+
+	second := &files.ModRoot{Name: "second", Path: filepath.Join(dir, "second")}
+	writeFiles(t, second.Path, map[string]string{
+		"common/traits/20_traits.txt": "brave = { }\n",
+		"events/00_events.txt":        "namespace = changed\n",
+		"gfx/icon.dds":                "icon",
+	})
+
+	return files.NewGameFS(vanilla, first, second)
+}
+
+func TestFind(t *testing.T) {
+	report, err := conflicts.Find(newTestGameFS(t), files.NewPathTable(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotFiles []string
+	for _, override := range report.Files {
+		line := override.Path + ": " + override.Winner.Root
+		for _, replaced := range override.Replaced {
+			line += " > " + replaced.Root
+			if replaced.Identical {
+				line += " (identical)"
+			}
+		}
+		gotFiles = append(gotFiles, line)
+	}
+	wantFiles := []string{
+		"events/00_events.txt: second > first > vanilla",
+		"gfx/icon.dds: second > vanilla (identical)",
+	}
+	if !reflect.DeepEqual(gotFiles, wantFiles) {
+		t.Errorf("file overrides = %v, want %v", gotFiles, wantFiles)
+	}
+
+	type object struct {
+		key    string
+		policy ast.KeyPolicy
+		roots  []string
+		winner string
+	}
+	var gotObjects []object
+	for _, override := range report.Objects {
+		obj := object{key: override.Folder + "/" + override.Key, policy: override.Policy}
+		for _, def := range override.Definitions {
+			obj.roots = append(obj.roots, def.Root)
+		}
+		if override.Winner != nil {
+			obj.winner = override.Winner.Root
+		}
+		gotObjects = append(gotObjects, obj)
+	}
+	// Variables and objects defined by the game alone aren't overrides
+	wantObjects := []object{
+		{key: "common/on_action/on_birth", policy: ast.Merge, roots: []string{"vanilla", "first"}},
+		{key: "common/traits/brave", policy: ast.LastWins, roots: []string{"vanilla", "first", "second"}, winner: "second"},
+	}
+	if !reflect.DeepEqual(gotObjects, wantObjects) {
+		t.Errorf("object overrides = %+v, want %+v", gotObjects, wantObjects)
+	}
+}
+
+func TestReport_WriteText(t *testing.T) {
+	report, err := conflicts.Find(newTestGameFS(t), files.NewPathTable(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"file gfx/icon.dds: [second] wins\n  replaces [vanilla] (identical)\n",
+		"object brave in common/traits (last-wins)\n",
+		"  > [second] common/traits/20_traits.txt:1:1\n",
+		"  [vanilla] common/traits/00_traits.txt:2:1\n",
+		"2 file override(s), 2 object override(s)\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package conflicts
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteText writes a human-readable report: each overridden file with the files it
+// replaces, then each overridden object with its definitions, the winner marked by ">".
+func (r *Report) WriteText(w io.Writer) error {
+	for _, override := range r.Files {
+		if _, err := fmt.Fprintf(w, "file %s: [%s] wins\n", override.Path, override.Winner.Root); err != nil {
+			return err
+		}
+		for _, replaced := range override.Replaced {
+			note := ""
+			if replaced.Identical {
+				note = " (identical)"
+			}
+			if _, err := fmt.Fprintf(w, "  replaces [%s]%s\n", replaced.Root, note); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, override := range r.Objects {
+		if _, err := fmt.Fprintf(w, "object %s in %s (%s)\n", override.Key, override.Folder, override.Policy); err != nil {
+			return err
+		}
+		for _, def := range override.Definitions {
+			mark := " "
+			if def == override.Winner {
+				mark = ">"
+			}
+			if _, err := fmt.Fprintf(w, "  %s %s\n", mark, def.Loc.Display(false)); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d file override(s), %d object override(s)\n", len(r.Files), len(r.Objects))
+	return err
+}
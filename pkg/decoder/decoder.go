@@ -0,0 +1,321 @@
+// Package decoder decodes parsed Paradox blocks into Go values, using "pdx" struct tags
+// to map keys to struct fields:
+//
+//	type Trait struct {
+//		Category string          `pdx:"category"`
+//		Cost     *values.Fixed   `pdx:"cost"`
+//		Opposite []string        `pdx:"opposites"`
+//		Modifier *ast.FieldBlock `pdx:"modifier"`
+//	}
+//
+//	var traits map[string]Trait
+//	err := decoder.Decode(tree.Block, &traits)
+//
+// Supported field types are strings, integers, floats, bools, values.Date, values.Fixed,
+// structs (and pointers to them), maps keyed by string, slices, and the raw AST types
+// *ast.FieldBlock, *ast.TokenBlock, *tokens.Token and ast.BlockOrValue.
+//
+// A slice collects every occurrence of a repeated key ("trait = a trait = b"), unless the
+// key appears once with a list of literals ("opposites = { a b }"), which fills the slice.
+package decoder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+	"github.com/unLomTrois/gock3/pkg/values"
+)
+
+// TagName is the struct tag used to map keys to struct fields.
+const TagName = "pdx"
+
+var (
+	blockOrValueType = reflect.TypeOf((*ast.BlockOrValue)(nil)).Elem()
+	fieldBlockType   = reflect.TypeOf((*ast.FieldBlock)(nil))
+	tokenBlockType   = reflect.TypeOf((*ast.TokenBlock)(nil))
+	tokenType        = reflect.TypeOf((*tokens.Token)(nil))
+	dateType         = reflect.TypeOf(values.Date{})
+	fixedType        = reflect.TypeOf(values.Fixed(0))
+)
+
+// Error describes a value that could not be decoded.
+type Error struct {
+	Loc tokens.Loc
+	Key string
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s: %v", e.Loc.Line, e.Loc.Column, e.Key, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Decoder decodes blocks into Go values.
+type Decoder struct {
+	policies *ast.Policies
+}
+
+// NewDecoder creates a Decoder that picks the occurrence of a repeated key decoded into a
+// single value according to policies. With nil policies the first occurrence is used.
+func NewDecoder(policies *ast.Policies) *Decoder {
+	return &Decoder{policies: policies}
+}
+
+// Decode decodes a block into the value pointed to by v with the default Decoder.
+func Decode(block *ast.FieldBlock, v any) error {
+	return NewDecoder(nil).Decode(block, v)
+}
+
+// Decode decodes a block into the value pointed to by v, which must be a non-nil pointer
+// to a struct or to a map keyed by string. Values that fail to decode are skipped and
+// reported together in the returned error.
+func (d *Decoder) Decode(block *ast.FieldBlock, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", v)
+	}
+
+	var errs []error
+	d.decodeBlock(block, d.policies, rv.Elem(), &errs)
+	return errors.Join(errs...)
+}
+
+// decodeBlock decodes the fields of a block into a struct or a map.
+func (d *Decoder) decodeBlock(block *ast.FieldBlock, policies *ast.Policies, rv reflect.Value, errs *[]error) {
+	switch rv.Kind() {
+	case reflect.Struct:
+		d.decodeStruct(block, policies, rv, errs)
+	case reflect.Map:
+		d.decodeMap(block, policies, rv, errs)
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		d.decodeBlock(block, policies, rv.Elem(), errs)
+	default:
+		*errs = append(*errs, &Error{Loc: block.Loc, Err: fmt.Errorf("cannot decode a block into %s", rv.Type())})
+	}
+}
+
+func (d *Decoder) decodeStruct(block *ast.FieldBlock, policies *ast.Policies, rv reflect.Value, errs *[]error) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		key := strings.Split(sf.Tag.Get(TagName), ",")[0]
+		if key == "" || key == "-" || !sf.IsExported() {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if isCollection(fv.Type()) {
+			d.decodeSlice(block.GetFields(key), policies.Block(key), fv, errs)
+			continue
+		}
+
+		if field := block.Lookup(key, policies); field != nil {
+			d.decodeField(field, policies.Block(key), fv, errs)
+		}
+	}
+}
+
+func (d *Decoder) decodeMap(block *ast.FieldBlock, policies *ast.Policies, rv reflect.Value, errs *[]error) {
+	rt := rv.Type()
+	if rt.Key().Kind() != reflect.String {
+		*errs = append(*errs, &Error{Loc: block.Loc, Err: fmt.Errorf("map keys must be strings, got %s", rt.Key())})
+		return
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(rt))
+	}
+
+	seen := make(map[string]bool)
+	for _, field := range block.Values {
+		key := field.Key.Value
+		// Script variables such as "@cost = 5" are not entries.
+		if seen[key] || strings.HasPrefix(key, "@") {
+			continue
+		}
+		seen[key] = true
+
+		elem := reflect.New(rt.Elem()).Elem()
+		if isCollection(rt.Elem()) {
+			d.decodeSlice(block.GetFields(key), policies.Block(key), elem, errs)
+		} else {
+			d.decodeField(block.Lookup(key, policies), policies.Block(key), elem, errs)
+		}
+		rv.SetMapIndex(reflect.ValueOf(key).Convert(rt.Key()), elem)
+	}
+}
+
+// decodeSlice decodes every occurrence of a key, or the single list of literals it holds.
+func (d *Decoder) decodeSlice(fields []*ast.Field, policies *ast.Policies, rv reflect.Value, errs *[]error) {
+	if len(fields) == 0 {
+		return
+	}
+
+	elemType := rv.Type().Elem()
+	if len(fields) == 1 && !isCollection(elemType) {
+		if list, ok := fields[0].Value.(*ast.TokenBlock); ok {
+			d.decodeList(fields[0], list, rv, errs)
+			return
+		}
+	}
+
+	slice := reflect.MakeSlice(rv.Type(), 0, len(fields))
+	for _, field := range fields {
+		elem := reflect.New(elemType).Elem()
+		d.decodeField(field, policies, elem, errs)
+		slice = reflect.Append(slice, elem)
+	}
+	rv.Set(slice)
+}
+
+// decodeList decodes a list of literals into a slice.
+func (d *Decoder) decodeList(field *ast.Field, list *ast.TokenBlock, rv reflect.Value, errs *[]error) {
+	slice := reflect.MakeSlice(rv.Type(), 0, len(list.Values))
+	for _, token := range list.Values {
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if err := decodeToken(token, elem); err != nil {
+			*errs = append(*errs, &Error{Loc: token.Loc, Key: field.Key.Value, Err: err})
+			continue
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	rv.Set(slice)
+}
+
+// decodeField decodes the value of a single field.
+func (d *Decoder) decodeField(field *ast.Field, policies *ast.Policies, rv reflect.Value, errs *[]error) {
+	if handled, err := decodeRaw(field.Value, rv); handled {
+		if err != nil {
+			*errs = append(*errs, &Error{Loc: field.GetLoc(), Key: field.Key.Value, Err: err})
+		}
+		return
+	}
+
+	var err error
+	switch value := field.Value.(type) {
+	case *tokens.Token:
+		err = decodeToken(value, rv)
+	case *ast.FieldBlock:
+		if rv.Type() == dateType || !isBlockTarget(rv.Type()) {
+			err = fmt.Errorf("expected a value, got a block")
+			break
+		}
+		d.decodeBlock(value, policies, rv, errs)
+	case *ast.TokenBlock:
+		if rv.Kind() != reflect.Slice {
+			err = fmt.Errorf("expected a value, got a list")
+			break
+		}
+		d.decodeList(field, value, rv, errs)
+	}
+
+	if err != nil {
+		*errs = append(*errs, &Error{Loc: field.GetLoc(), Key: field.Key.Value, Err: err})
+	}
+}
+
+// decodeRaw stores an AST value as-is if the target is one of the AST types.
+func decodeRaw(value ast.BlockOrValue, rv reflect.Value) (bool, error) {
+	vv := reflect.ValueOf(value)
+	switch rv.Type() {
+	case blockOrValueType:
+		rv.Set(vv)
+		return true, nil
+	case fieldBlockType, tokenBlockType, tokenType:
+		if vv.Type() != rv.Type() {
+			return true, fmt.Errorf("expected %s, got %s", rv.Type(), vv.Type())
+		}
+		rv.Set(vv)
+		return true, nil
+	}
+	return false, nil
+}
+
+// decodeToken decodes a literal token into a scalar value.
+func decodeToken(token *tokens.Token, rv reflect.Value) error {
+	if rv.Kind() == reflect.Pointer {
+		elem := reflect.New(rv.Type().Elem())
+		if err := decodeToken(token, elem.Elem()); err != nil {
+			return err
+		}
+		rv.Set(elem)
+		return nil
+	}
+
+	switch rv.Type() {
+	case dateType:
+		date, err := token.DateValue()
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(date))
+		return nil
+	case fixedType:
+		fixed, err := token.FixedValue()
+		if err != nil && !errors.Is(err, values.ErrPrecisionLoss) {
+			return err
+		}
+		rv.Set(reflect.ValueOf(fixed))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(token.Value)
+	case reflect.Bool:
+		b, err := token.BoolValue()
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := token.IntValue()
+		if err != nil {
+			return err
+		}
+		if rv.OverflowInt(n) {
+			return fmt.Errorf("integer %d overflows %s", n, rv.Type())
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := token.IntValue()
+		if err != nil {
+			return err
+		}
+		if n < 0 || rv.OverflowUint(uint64(n)) {
+			return fmt.Errorf("integer %d overflows %s", n, rv.Type())
+		}
+		rv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		f, err := token.FloatValue()
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	default:
+		return fmt.Errorf("cannot decode %q into %s", token.Value, rv.Type())
+	}
+	return nil
+}
+
+// isCollection reports whether values of type t collect several occurrences of a key.
+func isCollection(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice
+}
+
+// isBlockTarget reports whether a block can be decoded into values of type t.
+func isBlockTarget(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+}
@@ -0,0 +1,114 @@
+package decoder_test
+
+import (
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/testutil"
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/decoder"
+	"github.com/unLomTrois/gock3/pkg/values"
+)
+
+type modifier struct {
+	Parameter string `pdx:"parameter"`
+}
+
+type character struct {
+	Name     string           `pdx:"name"`
+	Female   bool             `pdx:"female"`
+	Martial  int              `pdx:"martial"`
+	Cost     values.Fixed     `pdx:"cost"`
+	Traits   []string         `pdx:"trait"`
+	Color    []int            `pdx:"color"`
+	Born     values.Date      `pdx:"born"`
+	Modifier *modifier        `pdx:"modifier"`
+	Effect   *ast.FieldBlock  `pdx:"effect"`
+	Desc     ast.BlockOrValue `pdx:"desc"`
+	Missing  *modifier        `pdx:"missing"`
+	Ignored  string
+}
+
+func TestDecode(t *testing.T) {
+	tree := testutil.ParseString(t, `70027 = {
+	name = "Teresa"
+	female = yes
+	martial = 5
+	cost = 0.25
+	trait = brave
+	trait = just
+	color = { 255 0 128 }
+	born = 941.1.1
+	modifier = { parameter = brave_bonus }
+	effect = { add_gold = 5 }
+	desc = { first_valid = { desc = a } }
+}
+`)
+
+	var characters map[string]character
+	if err := decoder.Decode(tree.Block, &characters); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	c, ok := characters["70027"]
+	if !ok {
+		t.Fatalf("Decode() did not decode entry 70027: %v", characters)
+	}
+	if c.Name != "Teresa" || !c.Female || c.Martial != 5 || c.Cost != 250 {
+		t.Errorf("scalars = %+v", c)
+	}
+	if len(c.Traits) != 2 || c.Traits[0] != "brave" || c.Traits[1] != "just" {
+		t.Errorf("Traits = %v, want [brave just]", c.Traits)
+	}
+	if len(c.Color) != 3 || c.Color[2] != 128 {
+		t.Errorf("Color = %v, want [255 0 128]", c.Color)
+	}
+	if c.Born != (values.Date{Year: 941, Month: 1, Day: 1}) {
+		t.Errorf("Born = %v, want 941.1.1", c.Born)
+	}
+	if c.Modifier == nil || c.Modifier.Parameter != "brave_bonus" {
+		t.Errorf("Modifier = %+v", c.Modifier)
+	}
+	if c.Effect == nil || c.Effect.GetField("add_gold") == nil {
+		t.Errorf("Effect = %+v", c.Effect)
+	}
+	if _, ok := c.Desc.(*ast.FieldBlock); !ok {
+		t.Errorf("Desc = %T, want *ast.FieldBlock", c.Desc)
+	}
+	if c.Missing != nil {
+		t.Errorf("Missing = %+v, want nil", c.Missing)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tree := testutil.ParseString(t, "martial = high\nfemale = maybe\nname = { a = b }\n")
+
+	var c character
+	err := decoder.Decode(tree.Block, &c)
+	if err == nil {
+		t.Fatal("Decode() expected an error")
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 3 {
+		t.Errorf("Decode() error = %v, want 3 errors", err)
+	}
+}
+
+func TestDecode_Policies(t *testing.T) {
+	tree := testutil.ParseString(t, "name = first\nname = second\n")
+
+	var c character
+	if err := decoder.NewDecoder(&ast.Policies{Default: ast.LastWins}).Decode(tree.Block, &c); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if c.Name != "second" {
+		t.Errorf("Name = %q, want %q", c.Name, "second")
+	}
+}
+
+func TestDecode_InvalidTarget(t *testing.T) {
+	var c character
+	if err := decoder.Decode(&ast.FieldBlock{}, c); err == nil {
+		t.Error("Decode() expected an error for a non-pointer target")
+	}
+}
@@ -0,0 +1,253 @@
+// Package descriptor reads mod descriptors: the descriptor.mod file inside a mod, and
+// the .mod file next to the mod folder that the launcher reads, e.g.
+//
+//	name = "Better Traits"
+//	version = "1.2"
+//	supported_version = "1.12.*"
+//	tags = { "Gameplay" "Fixes" }
+//	path = "mod/better_traits"
+//	replace_path = "common/traits"
+//	picture = "thumbnail.png"
+package descriptor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// Filename is the name of the descriptor inside a mod folder.
+const Filename = "descriptor.mod"
+
+const (
+	errMissingField            = "Descriptor has no %q field"
+	errInvalidSupportedVersion = "Invalid supported_version %q, expected numbers separated by dots, ending with wildcards such as \"1.12.*\""
+	errUnsupportedGameVersion  = "Mod supports game version %s, not %s"
+	errMissingModPath          = "Mod folder %q doesn't exist"
+	errMissingPicture          = "Thumbnail %q doesn't exist in the mod folder"
+)
+
+// supportedVersionPattern matches versions such as "1.12.4", "1.12.*" or "*".
+var supportedVersionPattern = regexp.MustCompile(`^(\d+|\*)(\.(\d+|\*))*$`)
+
+// Descriptor is the metadata of a mod.
+type Descriptor struct {
+	Name             string
+	Version          string
+	SupportedVersion string
+	Tags             []string
+	// Path is the mod folder as written, relative to the user directory of the game.
+	Path         string
+	ReplacePaths []string
+	Dependencies []string
+	Picture      string
+	RemoteFileID string
+
+	file   files.ParadoxFile
+	fields map[string]*ast.Field
+}
+
+// Parse reads a descriptor file. Syntax errors and missing required fields are
+// returned as diagnostics; the error is only set if the file can't be read.
+func Parse(path string) (*Descriptor, []*report.DiagnosticItem, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil, err
+	}
+	return ParseFile(files.NewParadoxTxtFile(path, files.Mod))
+}
+
+// ParseFile is like Parse for a file that may not be on disk, e.g. in an archive.
+func ParseFile(file files.ParadoxFile) (*Descriptor, []*report.DiagnosticItem, error) {
+	tree, diagnostics, err := parser.ParseParadoxFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := New(tree, file)
+	return d, append(diagnostics, d.validate()...), nil
+}
+
+// New builds the descriptor of a parsed descriptor file.
+func New(tree *ast.AST, file files.ParadoxFile) *Descriptor {
+	d := &Descriptor{file: file, fields: make(map[string]*ast.Field)}
+	if tree == nil || tree.Block == nil {
+		return d
+	}
+
+	for _, field := range tree.Block.Values {
+		key := field.Key.Value
+		if _, seen := d.fields[key]; !seen {
+			d.fields[key] = field
+		}
+
+		switch key {
+		case "name":
+			d.Name = literal(field)
+		case "version":
+			d.Version = literal(field)
+		case "supported_version":
+			d.SupportedVersion = literal(field)
+		case "path":
+			d.Path = literal(field)
+		case "picture":
+			d.Picture = literal(field)
+		case "remote_file_id":
+			d.RemoteFileID = literal(field)
+		case "replace_path":
+			d.ReplacePaths = append(d.ReplacePaths, literal(field))
+		case "tags":
+			d.Tags = list(field)
+		case "dependencies":
+			d.Dependencies = list(field)
+		}
+	}
+	return d
+}
+
+// FullPath returns the path of the descriptor file.
+func (d *Descriptor) FullPath() string {
+	return d.file.FullPath()
+}
+
+// IsInner reports whether the descriptor is the descriptor.mod inside a mod folder,
+// rather than the .mod file of the launcher.
+func (d *Descriptor) IsInner() bool {
+	return d.file.FileName() == Filename
+}
+
+// ModDir returns the mod folder: the folder of a descriptor.mod, or the path of a
+// .mod file, relative to the user directory containing the "mod" folder.
+func (d *Descriptor) ModDir() string {
+	if d.IsInner() || d.Path == "" {
+		return filepath.Dir(d.FullPath())
+	}
+	if filepath.IsAbs(d.Path) {
+		return filepath.Clean(d.Path)
+	}
+	userDir := filepath.Dir(filepath.Dir(d.FullPath()))
+	return filepath.Join(userDir, filepath.FromSlash(d.Path))
+}
+
+// ModRoot returns the mod as a layer of a GameFS.
+func (d *Descriptor) ModRoot() *files.ModRoot {
+	return &files.ModRoot{Name: d.Name, Path: d.ModDir(), ReplacePaths: d.ReplacePaths}
+}
+
+// validate reports the missing required fields. The launcher needs a path in .mod
+// files only, the folder of a descriptor.mod is its own.
+func (d *Descriptor) validate() []*report.DiagnosticItem {
+	required := []string{"name", "version", "supported_version"}
+	if !d.IsInner() {
+		required = append(required, "path")
+	}
+
+	var diagnostics []*report.DiagnosticItem
+	for _, key := range required {
+		if _, ok := d.fields[key]; !ok {
+			loc := tokens.LocFromParadoxFile(d.file)
+			diagnostics = append(diagnostics, report.FromLoc(*loc, codes.MissingDescriptorField, fmt.Sprintf(errMissingField, key)))
+		}
+	}
+	return diagnostics
+}
+
+// Check validates supported_version against the version of the game, if not empty,
+// and checks that the mod folder and its thumbnail exist.
+func (d *Descriptor) Check(gameVersion string) []*report.DiagnosticItem {
+	var diagnostics []*report.DiagnosticItem
+
+	if field, ok := d.fields["supported_version"]; ok {
+		if !ValidSupportedVersion(d.SupportedVersion) {
+			errMsg := fmt.Sprintf(errInvalidSupportedVersion, d.SupportedVersion)
+			diagnostics = append(diagnostics, d.fromValue(field, codes.InvalidSupportedVersion, errMsg))
+		} else if gameVersion != "" && !Supports(d.SupportedVersion, gameVersion) {
+			errMsg := fmt.Sprintf(errUnsupportedGameVersion, d.SupportedVersion, gameVersion)
+			diagnostics = append(diagnostics, d.fromValue(field, codes.UnsupportedGameVersion, errMsg))
+		}
+	}
+
+	modDir := d.ModDir()
+	if field, ok := d.fields["path"]; ok && !d.IsInner() {
+		if info, err := os.Stat(modDir); err != nil || !info.IsDir() {
+			diagnostics = append(diagnostics, d.fromValue(field, codes.MissingModPath, fmt.Sprintf(errMissingModPath, d.Path)))
+		}
+	}
+	if field, ok := d.fields["picture"]; ok && d.Picture != "" {
+		if _, err := os.Stat(filepath.Join(modDir, filepath.FromSlash(d.Picture))); err != nil {
+			diagnostics = append(diagnostics, d.fromValue(field, codes.MissingThumbnail, fmt.Sprintf(errMissingPicture, d.Picture)))
+		}
+	}
+
+	return diagnostics
+}
+
+// fromValue reports a diagnostic at the value of a field, or its key for block values.
+func (d *Descriptor) fromValue(field *ast.Field, code codes.Code, msg string) *report.DiagnosticItem {
+	if token, ok := field.Value.(*tokens.Token); ok {
+		return report.FromToken(token, code, msg)
+	}
+	return report.FromToken(field.Key, code, msg)
+}
+
+// ValidSupportedVersion reports whether a supported_version is made of numbers and
+// wildcards, with wildcards only at the end, e.g. "1.12.*".
+func ValidSupportedVersion(supported string) bool {
+	if !supportedVersionPattern.MatchString(supported) {
+		return false
+	}
+	wildcard := false
+	for _, part := range strings.Split(supported, ".") {
+		if part == "*" {
+			wildcard = true
+		} else if wildcard {
+			return false
+		}
+	}
+	return true
+}
+
+// Supports reports whether a supported_version matches a game version: every number
+// must match, wildcards match any number. "1.12.*" supports "1.12.4" but not "1.11.2".
+func Supports(supported, gameVersion string) bool {
+	want := strings.Split(supported, ".")
+	got := strings.Split(strings.TrimPrefix(gameVersion, "v"), ".")
+	for i, part := range want {
+		if part == "*" {
+			return true
+		}
+		if i >= len(got) || strings.TrimLeft(part, "0") != strings.TrimLeft(got[i], "0") {
+			return false
+		}
+	}
+	return true
+}
+
+// literal returns the value of a field holding a single token.
+func literal(field *ast.Field) string {
+	if token, ok := field.Value.(*tokens.Token); ok {
+		return token.Value
+	}
+	return ""
+}
+
+// list returns the values of a field holding a list of tokens.
+func list(field *ast.Field) []string {
+	block, ok := field.Value.(*ast.TokenBlock)
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(block.Values))
+	for _, token := range block.Values {
+		values = append(values, token.Value)
+	}
+	return values
+}
@@ -0,0 +1,179 @@
+package descriptor_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/descriptor"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func codesOf(diagnostics []*report.DiagnosticItem) []codes.Code {
+	var result []codes.Code
+	for _, diag := range diagnostics {
+		result = append(result, diag.Code)
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	// The layout of the user directory of the game
+	userDir := t.TempDir()
+	modDir := filepath.Join(userDir, "mod", "better_traits")
+	writeFile(t, filepath.Join(modDir, "thumbnail.png"), "")
+	path := filepath.Join(userDir, "mod", "better_traits.mod")
+	writeFile(t, path, `version = "1.2"
+tags = {
+	"Gameplay"
+	"Fixes"
+}
+name = "Better Traits"
+supported_version = "1.12.*"
+path = "mod/better_traits"
+replace_path = "common/traits"
+replace_path = "history/characters"
+dependencies = { "Community Flavor Pack" }
+picture = "thumbnail.png"
+remote_file_id = "2217567016"
+`)
+
+	d, diagnostics, err := descriptor.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics %v", codesOf(diagnostics))
+	}
+
+	want := &descriptor.Descriptor{
+		Name:             "Better Traits",
+		Version:          "1.2",
+		SupportedVersion: "1.12.*",
+		Tags:             []string{"Gameplay", "Fixes"},
+		Path:             "mod/better_traits",
+		ReplacePaths:     []string{"common/traits", "history/characters"},
+		Dependencies:     []string{"Community Flavor Pack"},
+		Picture:          "thumbnail.png",
+		RemoteFileID:     "2217567016",
+	}
+	// Only the exported fields are compared
+	got := &descriptor.Descriptor{
+		Name: d.Name, Version: d.Version, SupportedVersion: d.SupportedVersion, Tags: d.Tags,
+		Path: d.Path, ReplacePaths: d.ReplacePaths, Dependencies: d.Dependencies,
+		Picture: d.Picture, RemoteFileID: d.RemoteFileID,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if d.ModDir() != modDir {
+		t.Errorf("ModDir() = %s, want %s", d.ModDir(), modDir)
+	}
+	if root := d.ModRoot(); root.Path != modDir || len(root.ReplacePaths) != 2 {
+		t.Errorf("ModRoot() = %+v", root)
+	}
+	if diagnostics := d.Check("1.12.4"); len(diagnostics) != 0 {
+		t.Errorf("unexpected check diagnostics %v", codesOf(diagnostics))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []codes.Code
+	}{
+		{
+			name:    "descriptor.mod needs no path",
+			file:    "descriptor.mod",
+			content: "name = \"A\"\nversion = \"1\"\nsupported_version = \"1.12.*\"",
+		},
+		{
+			name:    "missing fields",
+			file:    "a.mod",
+			content: "name = \"A\"",
+			want:    []codes.Code{codes.MissingDescriptorField, codes.MissingDescriptorField, codes.MissingDescriptorField},
+		},
+		{
+			name:    "invalid supported_version",
+			file:    "descriptor.mod",
+			content: "name = \"A\"\nversion = \"1\"\nsupported_version = \"1.*.4\"",
+			want:    []codes.Code{codes.InvalidSupportedVersion},
+		},
+		{
+			name:    "unsupported game version",
+			file:    "descriptor.mod",
+			content: "name = \"A\"\nversion = \"1\"\nsupported_version = \"1.11.*\"",
+			want:    []codes.Code{codes.UnsupportedGameVersion},
+		},
+		{
+			name:    "missing folder and thumbnail",
+			file:    "a.mod",
+			content: "name = \"A\"\nversion = \"1\"\nsupported_version = \"1.12.*\"\npath = \"mod/a\"\npicture = \"thumbnail.png\"",
+			want:    []codes.Code{codes.MissingModPath, codes.MissingThumbnail},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mod", tt.file)
+			writeFile(t, path, tt.content)
+
+			d, diagnostics, err := descriptor.Parse(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diagnostics = append(diagnostics, d.Check("1.12.4")...)
+			if got := codesOf(diagnostics); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupports(t *testing.T) {
+	tests := []struct {
+		supported, game string
+		want            bool
+	}{
+		{"1.12.*", "1.12.4", true},
+		{"1.12.*", "1.12.4.1", true},
+		{"1.12.*", "1.11.2", false},
+		{"1.*", "1.12.4", true},
+		{"*", "2.0", true},
+		{"1.12.4", "1.12.4", true},
+		{"1.12.4", "1.12.5", false},
+		{"1.12.4", "v1.12.4", true},
+		{"1.12.4.1", "1.12.4", false},
+	}
+	for _, tt := range tests {
+		if got := descriptor.Supports(tt.supported, tt.game); got != tt.want {
+			t.Errorf("Supports(%q, %q) = %v, want %v", tt.supported, tt.game, got, tt.want)
+		}
+	}
+
+	for _, valid := range []string{"1.12.*", "1.*.*", "*", "1.12.4"} {
+		if !descriptor.ValidSupportedVersion(valid) {
+			t.Errorf("ValidSupportedVersion(%q) = false", valid)
+		}
+	}
+	for _, invalid := range []string{"", "1.*.4", "1.x", "1..2", "v1.12"} {
+		if descriptor.ValidSupportedVersion(invalid) {
+			t.Errorf("ValidSupportedVersion(%q) = true", invalid)
+		}
+	}
+}
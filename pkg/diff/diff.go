@@ -0,0 +1,305 @@
+// Package diff computes structural differences between two parsed Paradox files.
+//
+// Unlike a line-based diff, fields are matched by their key path, so reindented or
+// reordered blocks produce no changes. Repeated keys are matched by value (for
+// literals such as multiple "trait = x" lines) or by an identity key found inside
+// the block (such as "name" of an event option), falling back to their order.
+package diff
+
+import (
+	"strconv"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// PathSeparator separates the keys of a change path. Keys themselves may contain
+// dots (event ids, dates), so a slash is used instead.
+const PathSeparator = "/"
+
+// ChangeKind describes how a value differs between the old and the new tree.
+type ChangeKind uint8
+
+const (
+	// Added means the value only exists in the new tree.
+	Added ChangeKind = iota
+	// Removed means the value only exists in the old tree.
+	Removed
+	// Changed means the value exists in both trees but differs.
+	Changed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Value is one side of a change: the rendered PDX script and where it was found.
+type Value struct {
+	Text string     `json:"text"`
+	Loc  tokens.Loc `json:"loc"`
+}
+
+// Change is a single structural difference between two trees.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	Path string     `json:"path"`
+	Old  *Value     `json:"old,omitempty"`
+	New  *Value     `json:"new,omitempty"`
+}
+
+// Result holds all changes between two trees, in the order of the old tree.
+type Result struct {
+	Old     string    `json:"old"`
+	New     string    `json:"new"`
+	Changes []*Change `json:"changes"`
+}
+
+// Options controls how repeated keys are matched.
+type Options struct {
+	// IdentityKeys are keys whose literal value identifies a block among siblings
+	// sharing the same key, e.g. the "name" of an event option. They are tried in order.
+	IdentityKeys []string
+}
+
+// DefaultOptions matches repeated blocks by their "id" or "name" fields.
+var DefaultOptions = Options{
+	IdentityKeys: []string{"id", "name"},
+}
+
+// Compare returns the structural differences between the old and the new tree.
+func Compare(oldTree, newTree *ast.AST, opts Options) *Result {
+	d := &differ{opts: opts, changes: []*Change{}}
+	d.compareBlocks("", blockOf(oldTree), blockOf(newTree))
+
+	return &Result{
+		Old:     oldTree.Fullpath,
+		New:     newTree.Fullpath,
+		Changes: d.changes,
+	}
+}
+
+// HasChanges reports whether the trees differ.
+func (r *Result) HasChanges() bool {
+	return len(r.Changes) > 0
+}
+
+type differ struct {
+	opts    Options
+	changes []*Change
+}
+
+func blockOf(tree *ast.AST) *ast.FieldBlock {
+	if tree == nil || tree.Block == nil {
+		return &ast.FieldBlock{}
+	}
+	return tree.Block
+}
+
+// compareBlocks matches the fields of two blocks by key and compares each group.
+func (d *differ) compareBlocks(path string, oldBlock, newBlock *ast.FieldBlock) {
+	oldGroups, keys := groupByKey(oldBlock, nil)
+	newGroups, keys := groupByKey(newBlock, keys)
+
+	for _, key := range keys {
+		oldFields, newFields := oldGroups[key], newGroups[key]
+		keyPath := joinPath(path, key)
+
+		switch {
+		case len(oldFields) == 1 && len(newFields) == 1:
+			d.compareFields(keyPath, oldFields[0], newFields[0])
+		case len(newFields) == 0:
+			for i, field := range oldFields {
+				d.add(Removed, d.memberPath(keyPath, oldFields, i), field, nil)
+			}
+		case len(oldFields) == 0:
+			for j, field := range newFields {
+				d.add(Added, d.memberPath(keyPath, newFields, j), nil, field)
+			}
+		default:
+			d.compareRepeated(keyPath, oldFields, newFields)
+		}
+	}
+}
+
+// compareRepeated matches fields that share a key first by identity, then by order.
+func (d *differ) compareRepeated(path string, oldFields, newFields []*ast.Field) {
+	newMatched := make([]bool, len(newFields))
+	oldMatched := make([]bool, len(oldFields))
+
+	for i, oldField := range oldFields {
+		id := d.identity(oldField)
+		if id == "" {
+			continue
+		}
+		for j, newField := range newFields {
+			if newMatched[j] || d.identity(newField) != id {
+				continue
+			}
+			oldMatched[i], newMatched[j] = true, true
+			d.compareFields(path+"["+id+"]", oldField, newField)
+			break
+		}
+	}
+
+	// Literal values are their own identity, so leftovers are plain additions and removals.
+	// Blocks without a matching identity are paired up in order of appearance.
+	var oldRest, newRest []int
+	for i, field := range oldFields {
+		if oldMatched[i] {
+			continue
+		}
+		if isBlock(field.Value) {
+			oldRest = append(oldRest, i)
+		} else {
+			d.add(Removed, d.memberPath(path, oldFields, i), field, nil)
+		}
+	}
+	for j, field := range newFields {
+		if newMatched[j] {
+			continue
+		}
+		if isBlock(field.Value) {
+			newRest = append(newRest, j)
+		} else {
+			d.add(Added, d.memberPath(path, newFields, j), nil, field)
+		}
+	}
+
+	for len(oldRest) > 0 && len(newRest) > 0 {
+		i, j := oldRest[0], newRest[0]
+		d.compareFields(indexedPath(path, i), oldFields[i], newFields[j])
+		oldRest, newRest = oldRest[1:], newRest[1:]
+	}
+	for _, i := range oldRest {
+		d.add(Removed, indexedPath(path, i), oldFields[i], nil)
+	}
+	for _, j := range newRest {
+		d.add(Added, indexedPath(path, j), nil, newFields[j])
+	}
+}
+
+// compareFields compares two fields that were matched to each other.
+func (d *differ) compareFields(path string, oldField, newField *ast.Field) {
+	if operator(oldField) != operator(newField) {
+		d.add(Changed, path, oldField, newField)
+		return
+	}
+
+	oldBlock, oldIsBlock := oldField.Value.(*ast.FieldBlock)
+	newBlock, newIsBlock := newField.Value.(*ast.FieldBlock)
+	if oldIsBlock && newIsBlock {
+		d.compareBlocks(path, oldBlock, newBlock)
+		return
+	}
+
+	// Literals and token lists (such as colors) are compared as a whole.
+	if ast.FormatValue(oldField.Value) != ast.FormatValue(newField.Value) {
+		d.add(Changed, path, oldField, newField)
+	}
+}
+
+// operator returns the operator of a field, "=" for the nil operators of ASTs built by
+// hand, see ast.Field.
+func operator(field *ast.Field) string {
+	if field.Operator == nil {
+		return "="
+	}
+	return field.Operator.Value
+}
+
+// memberPath returns the path of the i-th field among fields sharing a key.
+func (d *differ) memberPath(path string, fields []*ast.Field, i int) string {
+	if len(fields) == 1 {
+		return path
+	}
+	if id := d.identity(fields[i]); id != "" {
+		return path + "[" + id + "]"
+	}
+	return indexedPath(path, i)
+}
+
+// identity returns the value identifying a field among siblings sharing its key.
+func (d *differ) identity(field *ast.Field) string {
+	switch value := field.Value.(type) {
+	case *tokens.Token:
+		return value.Value
+	case *ast.FieldBlock:
+		for _, key := range d.opts.IdentityKeys {
+			if token := value.GetFieldValue(key); token != nil {
+				return token.Value
+			}
+		}
+	}
+	return ""
+}
+
+func (d *differ) add(kind ChangeKind, path string, oldField, newField *ast.Field) {
+	d.changes = append(d.changes, &Change{
+		Kind: kind,
+		Path: path,
+		Old:  valueOf(oldField),
+		New:  valueOf(newField),
+	})
+}
+
+func valueOf(field *ast.Field) *Value {
+	if field == nil {
+		return nil
+	}
+	return &Value{
+		Text: ast.FormatValue(field.Value),
+		Loc:  field.GetLoc(),
+	}
+}
+
+// groupByKey groups the fields of a block by key, appending unseen keys to order.
+func groupByKey(block *ast.FieldBlock, order []string) (map[string][]*ast.Field, []string) {
+	groups := make(map[string][]*ast.Field)
+	if block == nil {
+		return groups, order
+	}
+
+	seen := make(map[string]bool, len(order))
+	for _, key := range order {
+		seen[key] = true
+	}
+
+	for _, field := range block.Values {
+		key := field.Key.Value
+		groups[key] = append(groups[key], field)
+		if !seen[key] {
+			seen[key] = true
+			order = append(order, key)
+		}
+	}
+	return groups, order
+}
+
+func isBlock(value ast.BlockOrValue) bool {
+	_, ok := value.(ast.Block)
+	return ok
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + PathSeparator + key
+}
+
+func indexedPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
@@ -0,0 +1,130 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/testutil"
+	"github.com/unLomTrois/gock3/pkg/diff"
+)
+
+type wantChange struct {
+	kind diff.ChangeKind
+	path string
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []wantChange
+	}{
+		{
+			name: "identical",
+			old:  "a = b\nc = { d = e }\n",
+			new:  "a = b\nc = { d = e }\n",
+			want: nil,
+		},
+		{
+			name: "reordered and reindented",
+			old:  "a = b\nc = {\n\td = e\n\tf = g\n}\n",
+			new:  "c = { f = g d = e }\na = b\n",
+			want: nil,
+		},
+		{
+			name: "changed nested value",
+			old:  "70027 = {\n\tculture = castilian\n}\n",
+			new:  "70027 = {\n\tculture = leonese\n}\n",
+			want: []wantChange{{diff.Changed, "70027/culture"}},
+		},
+		{
+			name: "added and removed top-level entries",
+			old:  "70027 = { name = Teresa }\n",
+			new:  "70028 = { name = Sancha }\n",
+			want: []wantChange{
+				{diff.Removed, "70027"},
+				{diff.Added, "70028"},
+			},
+		},
+		{
+			name: "repeated literal keys are matched by value",
+			old:  "c = {\n\ttrait = brave\n\ttrait = just\n}\n",
+			new:  "c = {\n\ttrait = just\n\ttrait = devoted\n\ttrait = brave\n}\n",
+			want: []wantChange{{diff.Added, "c/trait[devoted]"}},
+		},
+		{
+			name: "repeated blocks are matched by identity key",
+			old:  "e = {\n\toption = { name = a ai_chance = 1 }\n\toption = { name = b ai_chance = 2 }\n}\n",
+			new:  "e = {\n\toption = { name = b ai_chance = 5 }\n\toption = { name = a ai_chance = 1 }\n}\n",
+			want: []wantChange{{diff.Changed, "e/option[b]/ai_chance"}},
+		},
+		{
+			name: "repeated blocks without identity are matched by order",
+			old:  "h = {\n\t941.1.1 = { birth = yes }\n\t941.1.1 = { trait = brave }\n}\n",
+			new:  "h = {\n\t941.1.1 = { birth = yes }\n\t941.1.1 = { trait = just }\n}\n",
+			want: []wantChange{{diff.Changed, "h/941.1.1[1]/trait"}},
+		},
+		{
+			name: "token lists are compared as a whole",
+			old:  "color = { 255 255 255 }\n",
+			new:  "color = { 255 0 255 }\n",
+			want: []wantChange{{diff.Changed, "color"}},
+		},
+		{
+			name: "operator change",
+			old:  "a = 1\n",
+			new:  "a >= 1\n",
+			want: []wantChange{{diff.Changed, "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := diff.Compare(testutil.ParseString(t, tt.old), testutil.ParseString(t, tt.new), diff.DefaultOptions)
+
+			if len(result.Changes) != len(tt.want) {
+				for _, c := range result.Changes {
+					t.Logf("got change: %s %s", c.Kind, c.Path)
+				}
+				t.Fatalf("Compare() returned %d changes, want %d", len(result.Changes), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := result.Changes[i]
+				if got.Kind != want.kind || got.Path != want.path {
+					t.Errorf("change %d = %s %s, want %s %s", i, got.Kind, got.Path, want.kind, want.path)
+				}
+			}
+		})
+	}
+}
+
+func TestCompare_Locations(t *testing.T) {
+	oldTree := testutil.ParseString(t, "a = {\n\tb = c\n}\n")
+	newTree := testutil.ParseString(t, "a = {\n\n\tb = d\n}\n")
+
+	result := diff.Compare(oldTree, newTree, diff.DefaultOptions)
+	if len(result.Changes) != 1 {
+		t.Fatalf("Compare() returned %d changes, want 1", len(result.Changes))
+	}
+
+	change := result.Changes[0]
+	if change.Old.Text != "c" || change.New.Text != "d" {
+		t.Errorf("texts = %q -> %q, want %q -> %q", change.Old.Text, change.New.Text, "c", "d")
+	}
+	if change.Old.Loc.Line != 2 || change.New.Loc.Line != 3 {
+		t.Errorf("lines = %d -> %d, want 2 -> 3", change.Old.Loc.Line, change.New.Loc.Line)
+	}
+}
+
+func TestCompare_MissingOperator(t *testing.T) {
+	oldTree := testutil.ParseString(t, "a = 1\nb = 2\n")
+	newTree := testutil.ParseString(t, "a = 1\nb ?= 2\n")
+	// The parser never leaves an operator nil, ASTs built by hand may: it reads as "="
+	oldTree.Block.Values[0].Operator = nil
+	oldTree.Block.Values[1].Operator = nil
+
+	result := diff.Compare(oldTree, newTree, diff.DefaultOptions)
+	if len(result.Changes) != 1 || result.Changes[0].Kind != diff.Changed || result.Changes[0].Path != "b" {
+		t.Errorf("Compare() = %+v, want b changed", result.Changes)
+	}
+}
@@ -0,0 +1,55 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// maxTextWidth limits how much of a rendered value is printed in text output.
+const maxTextWidth = 80
+
+// WriteText writes a human-readable summary of the changes, one line per change,
+// prefixed with "+" for additions, "-" for removals and "~" for changed values.
+func (r *Result) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", r.Old, r.New); err != nil {
+		return err
+	}
+
+	for _, change := range r.Changes {
+		var err error
+		switch change.Kind {
+		case Added:
+			_, err = fmt.Fprintf(w, "+ %s = %s (%s)\n", change.Path, shorten(change.New.Text), formatLoc(change.New.Loc))
+		case Removed:
+			_, err = fmt.Fprintf(w, "- %s = %s (%s)\n", change.Path, shorten(change.Old.Text), formatLoc(change.Old.Loc))
+		case Changed:
+			_, err = fmt.Fprintf(w, "~ %s: %s -> %s (%s -> %s)\n", change.Path,
+				shorten(change.Old.Text), shorten(change.New.Text),
+				formatLoc(change.Old.Loc), formatLoc(change.New.Loc))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d change(s)\n", len(r.Changes))
+	return err
+}
+
+func formatLoc(loc tokens.Loc) string {
+	filename, err := loc.Filename()
+	if err != nil {
+		return fmt.Sprintf("%d:%d", loc.Line, loc.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", filename, loc.Line, loc.Column)
+}
+
+func shorten(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxTextWidth {
+		return text
+	}
+	return string(runes[:maxTextWidth-3]) + "..."
+}
@@ -0,0 +1,108 @@
+package files
+
+import (
+	"archive/zip"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveSep separates the path of an archive from the path of a file in it, as in
+// "mod.zip!/common/traits/00_traits.txt".
+const ArchiveSep = "!"
+
+// gameFolders are the top-level folders of the game, an archive holding only one of
+// them is not a mod nested in a folder.
+var gameFolders = map[string]bool{
+	"common": true, "content_source": true, "data_binding": true, "dlc": true,
+	"events": true, "fonts": true, "gfx": true, "gui": true, "history": true,
+	"localization": true, "map_data": true, "music": true, "notifications": true,
+	"sound": true, "tests": true, "tools": true, "tweakergui_assets": true,
+}
+
+// IsGameFolder reports whether a name is a top-level folder of the game, e.g. "common".
+func IsGameFolder(name string) bool {
+	return gameFolders[name]
+}
+
+// Archive is a mod packed in a zip archive, open until Close.
+type Archive struct {
+	// Mod is the mod in the archive, its FS holds the files of the mod and its Path,
+	// e.g. "mod.zip!", starts the paths shown in locations.
+	Mod *ModRoot
+
+	reader *zip.ReadCloser
+}
+
+// IsArchive reports whether a path names a zip archive.
+func IsArchive(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".zip")
+}
+
+// OpenArchive opens a mod packed in a zip archive. Archives holding a single folder
+// with the mod, e.g. "better_traits/common/...", are opened at that folder. The mod
+// is named after the archive.
+func OpenArchive(name string) (*Archive, error) {
+	reader, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys, prefix, err := modFolder(reader)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	root := name + ArchiveSep
+	if prefix != "." {
+		root = filepath.Join(root, filepath.FromSlash(prefix))
+	}
+	mod := &ModRoot{
+		Name: strings.TrimSuffix(filepath.Base(name), filepath.Ext(name)),
+		Path: root,
+		FS:   fsys,
+	}
+	return &Archive{Mod: mod, reader: reader}, nil
+}
+
+// Close closes the archive, its files can't be read anymore.
+func (a *Archive) Close() error {
+	return a.reader.Close()
+}
+
+// modFolder descends into the single folder of an archive as long as it doesn't look
+// like the files of a mod, and returns the file system of the folder with its path.
+func modFolder(fsys fs.FS) (fs.FS, string, error) {
+	prefix := "."
+	for {
+		entries, err := fs.ReadDir(fsys, prefix)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var dirs []string
+		onlyDirs := true
+		for _, entry := range entries {
+			switch {
+			case entry.Name() == "__MACOSX":
+				// Metadata added by macOS
+			case entry.IsDir():
+				dirs = append(dirs, entry.Name())
+			default:
+				onlyDirs = false
+			}
+		}
+		if len(dirs) != 1 || !onlyDirs || gameFolders[dirs[0]] {
+			break
+		}
+		prefix = path.Join(prefix, dirs[0])
+	}
+
+	if prefix == "." {
+		return fsys, prefix, nil
+	}
+	sub, err := fs.Sub(fsys, prefix)
+	return sub, prefix, err
+}
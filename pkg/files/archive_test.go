@@ -0,0 +1,94 @@
+package files
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeZip creates a zip archive of the files, with their names as content.
+func writeZip(t *testing.T, path string, names ...string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for _, name := range names {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenArchive(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		wantPath string
+	}{
+		{
+			name:     "flat",
+			files:    []string{"descriptor.mod", "common/traits/00_traits.txt"},
+			wantPath: "",
+		},
+		{
+			name:     "nested",
+			files:    []string{"better_traits/descriptor.mod", "better_traits/common/traits/00_traits.txt", "__MACOSX/._better_traits"},
+			wantPath: "better_traits",
+		},
+		{
+			name:     "game folder only",
+			files:    []string{"common/traits/00_traits.txt"},
+			wantPath: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "better_traits.zip")
+			writeZip(t, path, tt.files...)
+
+			archive, err := OpenArchive(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer archive.Close()
+
+			mod := archive.Mod
+			if want := filepath.Join(path+ArchiveSep, tt.wantPath); mod.Path != want || mod.Name != "better_traits" {
+				t.Errorf("mod = %s at %s, want better_traits at %s", mod.Name, mod.Path, want)
+			}
+			content, err := fs.ReadFile(mod.FS, "common/traits/00_traits.txt")
+			if err != nil || string(content) != filepath.ToSlash(filepath.Join(tt.wantPath, "common/traits/00_traits.txt")) {
+				t.Errorf("ReadFile() = %q, %v", content, err)
+			}
+
+			entry, err := NewGameFS("", mod).Resolve("common/traits/00_traits.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(path+ArchiveSep, tt.wantPath, "common", "traits", "00_traits.txt"); entry.FullPath != want {
+				t.Errorf("FullPath = %s, want %s", entry.FullPath, want)
+			}
+			if rel, _ := entry.File().StoreInPathTable().Relpath(); rel != "common/traits/00_traits.txt" {
+				t.Errorf("Relpath() = %s", rel)
+			}
+		})
+	}
+
+	if _, err := OpenArchive(filepath.Join(t.TempDir(), "missing.zip")); err == nil {
+		t.Errorf("expected an error for a missing archive")
+	}
+}
@@ -0,0 +1,77 @@
+package files
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ScriptExt is the extension of the script files found in directories.
+const ScriptExt = ".txt"
+
+// Discover expands files, directories and glob patterns into the list of files to
+// parse. Files are kept whatever their extension, directories are walked for .txt
+// files, and glob matches are expanded the same way. The result is sorted and has no
+// duplicates. A path or pattern matching nothing is an error wrapping fs.ErrNotExist.
+func Discover(patterns ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(path string) {
+		key := filepath.Clean(path)
+		if abs, err := filepath.Abs(key); err == nil {
+			key = abs
+		}
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, filepath.Clean(path))
+		}
+	}
+
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if isGlob(pattern) {
+			var err error
+			matches, err = filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, &fs.PathError{Op: "discover", Path: pattern, Err: fs.ErrNotExist}
+			}
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ScriptExt) {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+// isGlob reports whether a path has glob metacharacters, see filepath.Match.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
@@ -0,0 +1,78 @@
+package files
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir,
+		"common/traits/00_traits.txt",
+		"common/traits/01_traits.TXT",
+		"common/traits/readme.md",
+		"common/religions/00_religions.txt",
+		"events/00_events.txt",
+	)
+	join := func(names ...string) []string {
+		var paths []string
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+		wantErr  error
+	}{
+		{
+			name:     "directory",
+			patterns: join("common"),
+			want:     join("common/religions/00_religions.txt", "common/traits/00_traits.txt", "common/traits/01_traits.TXT"),
+		},
+		{
+			name:     "files of any extension",
+			patterns: join("common/traits/readme.md", "events/00_events.txt"),
+			want:     join("common/traits/readme.md", "events/00_events.txt"),
+		},
+		{
+			name:     "glob and duplicates",
+			patterns: append(join("common/*/00_*.txt"), join("common/traits")...),
+			want:     join("common/religions/00_religions.txt", "common/traits/00_traits.txt", "common/traits/01_traits.TXT"),
+		},
+		{
+			name:     "missing file",
+			patterns: join("missing.txt"),
+			wantErr:  fs.ErrNotExist,
+		},
+		{
+			name:     "glob without matches",
+			patterns: join("gfx/*.txt"),
+			wantErr:  fs.ErrNotExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Discover(tt.patterns...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,81 +2,167 @@ package files
 
 import (
 	"errors"
+	"io/fs"
 	"sync"
 )
 
-// Error to be returned if the index is out of bounds
-var ErrIndexOutOfBounds = errors.New("index out of bounds")
+// ErrUnknownPath is returned when looking up an index that isn't stored in the table.
+var ErrUnknownPath = errors.New("path not in the path table")
 
+// PathTableIndex identifies a stored path. It is as cheap to copy and compare as a
+// pointer, so every tokens.Loc can carry one. The zero value refers to no path.
 type PathTableIndex struct {
-	index uint32
+	path *storedPath
 }
 
-type PathTable interface {
-	Store(fullpath string) PathTableIndex
+// storedPath is an entry of a PathTable, or of no table for detached files.
+type storedPath struct {
+	table    *PathTable
+	fullpath string
+	// root is the root the file was loaded from, nil if unknown, and rel the path
+	// relative to it.
+	root *Root
+	rel  string
+	// source reads the content of the file
+	source source
 }
 
-type PathTableStore struct {
-	fullpath string
+func newStoredPath(table *PathTable, root *Root, fullpath string, src source) *storedPath {
+	stored := &storedPath{table: table, fullpath: fullpath, source: src}
+	if root != nil {
+		if rel, ok := root.Rel(fullpath); ok {
+			rootCopy := *root
+			stored.root, stored.rel = &rootCopy, rel
+		}
+	}
+	return stored
 }
 
-// Singleton of PathTable
-type pathTable struct {
-	paths []PathTableStore
-	mu    sync.RWMutex
+// Fullpath returns the path the index refers to.
+func (idx PathTableIndex) Fullpath() (string, error) {
+	if idx.path == nil {
+		return "", ErrUnknownPath
+	}
+	return idx.path.fullpath, nil
 }
 
-type PathTableStatic struct{}
+// ReadFile returns the content of the file the index refers to, from the file system
+// it was loaded from.
+func (idx PathTableIndex) ReadFile() ([]byte, error) {
+	if idx.path == nil {
+		return nil, ErrUnknownPath
+	}
+	return idx.path.source.readFile(idx.path.fullpath)
+}
 
-var (
-	pathTableInstance *pathTable
-	once              sync.Once
-	PATHTABLE         PathTableStatic
-)
+// Stat describes the file the index refers to, from the file system it was loaded from.
+func (idx PathTableIndex) Stat() (fs.FileInfo, error) {
+	if idx.path == nil {
+		return nil, ErrUnknownPath
+	}
+	return idx.path.source.stat(idx.path.fullpath)
+}
 
-func GetPathTableInstance() *pathTable {
-	once.Do(func() {
-		pathTableInstance = &pathTable{
-			paths: make([]PathTableStore, 0),
-			mu:    sync.RWMutex{},
-		}
-	})
-	return pathTableInstance
+// Root returns the root the file was loaded from, if known.
+func (idx PathTableIndex) Root() (Root, bool) {
+	if idx.path == nil || idx.path.root == nil {
+		return Root{}, false
+	}
+	return *idx.path.root, true
+}
+
+// Relpath returns the slash-separated path of the file relative to its root, or the
+// full path if the root is unknown.
+func (idx PathTableIndex) Relpath() (string, error) {
+	if idx.path == nil {
+		return "", ErrUnknownPath
+	}
+	if idx.path.root == nil {
+		return idx.path.fullpath, nil
+	}
+	return idx.path.rel, nil
+}
+
+// ContentReader reads the content of the files of a PathTable, e.g. through a cache.
+type ContentReader interface {
+	ReadFile(idx PathTableIndex) ([]byte, error)
+}
+
+// PathTable stores the paths of the files of a workspace, each path once. Dropping the
+// table, and the locations referring to it, releases its paths.
+type PathTable struct {
+	mu    sync.RWMutex
+	paths map[string]*storedPath
+	// reader, if set, reads the content of the files of the table
+	reader ContentReader
+}
+
+// NewPathTable creates an empty PathTable.
+func NewPathTable() *PathTable {
+	return &PathTable{paths: make(map[string]*storedPath)}
+}
+
+// Store returns the index of a path, storing it if it's not in the table yet.
+func (pt *PathTable) Store(fullpath string) PathTableIndex {
+	return pt.StoreWithRoot(nil, fullpath)
 }
 
-// Store is usually called from PATHTABLE, which is a "static namespace" of the package.
-// Hence public Store method should call GetPathTableInstance to get an actual singleton, and call its private store method
-func (PathTableStatic) Store(fullpath string) *PathTableIndex {
-	return GetPathTableInstance().store(fullpath)
+// StoreWithRoot is like Store, and records the root of the file if it lies under it.
+// A path keeps the root it was first stored with.
+func (pt *PathTable) StoreWithRoot(root *Root, fullpath string) PathTableIndex {
+	return pt.store(root, fullpath, source{})
 }
 
-func (pt *pathTable) store(fullpath string) *PathTableIndex {
+// store stores a path with the source of its content.
+func (pt *PathTable) store(root *Root, fullpath string, src source) PathTableIndex {
+	pt.mu.RLock()
+	stored, ok := pt.paths[fullpath]
+	pt.mu.RUnlock()
+	if ok {
+		return PathTableIndex{path: stored}
+	}
+
 	pt.mu.Lock()
 	defer pt.mu.Unlock()
-	idx := &PathTableIndex{index: uint32(len(pt.paths))}
-	pt.paths = append(pt.paths, PathTableStore{fullpath: fullpath})
-	return idx
+	if stored, ok := pt.paths[fullpath]; ok {
+		return PathTableIndex{path: stored}
+	}
+	stored = newStoredPath(pt, root, fullpath, src)
+	pt.paths[fullpath] = stored
+	return PathTableIndex{path: stored}
 }
 
-// Public LookupFullpath method that calls the private method after getting the singleton instance.
-func (PathTableStatic) LookupFullpath(index PathTableIndex) (string, error) {
-	return GetPathTableInstance().lookupFullpath(index)
+// LookupFullpath returns the path of an index stored in this table.
+func (pt *PathTable) LookupFullpath(index PathTableIndex) (string, error) {
+	if index.path == nil || index.path.table != pt {
+		return "", ErrUnknownPath
+	}
+	return index.path.fullpath, nil
+}
+
+// SetReader makes the files of the table read their content through r, e.g. a cache
+// shared with the reporters. PathTableIndex.ReadFile still reads the file itself.
+func (pt *PathTable) SetReader(r ContentReader) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.reader = r
 }
 
-// Private lookupFullpath method with RLock and RUnlock for thread-safe read access.
-func (pt *pathTable) lookupFullpath(index PathTableIndex) (string, error) {
+func (pt *PathTable) contentReader() ContentReader {
 	pt.mu.RLock()
 	defer pt.mu.RUnlock()
+	return pt.reader
+}
 
-	if index.index >= uint32(len(pt.paths)) {
-		return "", ErrIndexOutOfBounds
-	}
-
-	return pt.paths[index.index].fullpath, nil
+// Len returns the number of stored paths.
+func (pt *PathTable) Len() int {
+	pt.mu.RLock()
+	defer pt.mu.RUnlock()
+	return len(pt.paths)
 }
 
-// ResetPathTable is a helper function to reset the singleton for testing purposes.
-func resetPathTable() {
-	pathTableInstance = GetPathTableInstance()
-	pathTableInstance.paths = make([]PathTableStore, 0)
+// detached returns the index of a path outside of any table, for files that don't
+// belong to a workspace.
+func detached(root *Root, fullpath string, src source) PathTableIndex {
+	return PathTableIndex{path: newStoredPath(nil, root, fullpath, src)}
 }
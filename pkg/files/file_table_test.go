@@ -1,246 +1,142 @@
 package files
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
-	"reflect"
 	"sync"
 	"testing"
 )
 
-func TestGetPathTableInstance(t *testing.T) {
-	resetPathTable()
+func TestPathTable_Store(t *testing.T) {
+	pt := NewPathTable()
 
-	tests := []struct {
-		name string
-		want *pathTable
-	}{
-		{
-			name: "Check Singleton Instance",
-			want: GetPathTableInstance(), // Expect the same instance to be returned
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := GetPathTableInstance()
-			if got != tt.want { // Compare instances (pointer equality check)
-				t.Errorf("GetPathTableInstance() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_pathTable_Store(t *testing.T) {
-	// Reset the singleton before the test starts
-	resetPathTable()
-
-	type args struct {
-		fullpath string
-	}
-	tests := []struct {
-		name string
-		args args
-		want PathTableIndex
-	}{
-		{
-			name: "Store new path",
-			args: args{
-				fullpath: filepath.Join("full", "path"),
-			},
-			want: PathTableIndex{index: 0}, // Expected index after first insertion
-		},
-		{
-			name: "Store second path",
-			args: args{
-				fullpath: filepath.Join("full2", "path"),
-			},
-			want: PathTableIndex{index: 1}, // Expected index after second insertion
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Use PATHTABLE to store paths, which will call GetPathTableInstance() internally
-			got := PATHTABLE.Store(tt.args.fullpath)
-			if got.index != tt.want.index {
-				t.Errorf("PATHTABLE.Store() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
+	first := pt.Store(filepath.Join("full", "path"))
+	second := pt.Store(filepath.Join("full2", "path"))
+	again := pt.Store(filepath.Join("full", "path"))
 
-func Test_pathTable_store(t *testing.T) {
-	resetPathTable()
-
-	type args struct {
-		fullpath string
+	if first == second {
+		t.Errorf("different paths share the index %v", first)
 	}
-	tests := []struct {
-		name string
-		pt   *pathTable
-		args args
-		want *PathTableIndex
-	}{
-		{
-			name: "Store new path",
-			pt:   GetPathTableInstance(),
-			args: args{
-				fullpath: filepath.Join("full", "path"),
-			},
-			want: &PathTableIndex{index: 0}, // Expected index after first insertion
-		},
-		{
-			name: "Store second path",
-			pt:   GetPathTableInstance(),
-			args: args{
-				fullpath: filepath.Join("full2", "path"),
-			},
-			want: &PathTableIndex{index: 1}, // Expected index after second insertion
-		},
+	if first != again {
+		t.Errorf("storing a path again gave a new index: %v, want %v", again, first)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := tt.pt.store(tt.args.fullpath)
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("pathTable.store() = %v, want %v", got, tt.want)
-			}
-		})
+	if pt.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", pt.Len())
 	}
 }
 
-func Test_pathTable_Store_Concurrent(t *testing.T) {
-	resetPathTable()
+func TestPathTable_Store_Concurrent(t *testing.T) {
+	pt := NewPathTable()
 
 	var wg sync.WaitGroup
 	numGoroutines := 10
+	indices := make([]PathTableIndex, numGoroutines)
 
-	// Expected number of paths stored should match the number of goroutines
-	expectedPaths := numGoroutines
-
-	// Run Store method concurrently in multiple goroutines
+	// Every path is stored by two goroutines
 	for i := 0; i < numGoroutines; i++ {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-
-			fullPath := filepath.Join(fmt.Sprintf("full%d", i), "path")
-
-			// Use PATHTABLE.Store to store paths concurrently
-			PATHTABLE.Store(fullPath)
+			indices[i] = pt.Store(filepath.Join(fmt.Sprintf("full%d", i/2), "path"))
 		}(i)
 	}
-
-	// Wait for all goroutines to complete
 	wg.Wait()
 
-	// Check the total number of stored paths in the pathTable
-	pt := GetPathTableInstance() // Get the singleton instance
-	pt.mu.RLock()                // Use read lock since we are only reading data
-	defer pt.mu.RUnlock()
-
-	if len(pt.paths) != expectedPaths {
-		t.Errorf("Concurrent Store failed: expected %d paths, got %d", expectedPaths, len(pt.paths))
+	if pt.Len() != numGoroutines/2 {
+		t.Errorf("Concurrent Store failed: expected %d paths, got %d", numGoroutines/2, pt.Len())
+	}
+	for i := 0; i < numGoroutines; i += 2 {
+		if indices[i] != indices[i+1] {
+			t.Errorf("path %d got two indices", i/2)
+		}
 	}
 }
 
-func Test_pathTable_LookupFullpath(t *testing.T) {
-	resetPathTable()
-
-	// Store some paths for testing.
-	PATHTABLE.Store(filepath.Join("full1", "path"))
-	PATHTABLE.Store(filepath.Join("full2", "path"))
+func TestPathTable_LookupFullpath(t *testing.T) {
+	pt := NewPathTable()
+	first := pt.Store(filepath.Join("full1", "path"))
+	second := pt.Store(filepath.Join("full2", "path"))
+	other := NewPathTable().Store(filepath.Join("full1", "path"))
 
-	type args struct {
-		index PathTableIndex
-	}
 	tests := []struct {
 		name    string
-		args    args
+		index   PathTableIndex
 		want    string
-		wantErr bool
+		wantErr error
 	}{
-		{
-			name: "Lookup first full path",
-			args: args{
-				index: PathTableIndex{index: 0},
-			},
-			want:    filepath.Join("full1", "path"),
-			wantErr: false,
-		},
-		{
-			name: "Lookup second full path",
-			args: args{
-				index: PathTableIndex{index: 1},
-			},
-			want:    filepath.Join("full2", "path"),
-			wantErr: false,
-		},
-		{
-			name: "Lookup out of bounds",
-			args: args{
-				index: PathTableIndex{index: 2},
-			},
-			want:    "",
-			wantErr: true,
-		},
+		{name: "Lookup first full path", index: first, want: filepath.Join("full1", "path")},
+		{name: "Lookup second full path", index: second, want: filepath.Join("full2", "path")},
+		{name: "Lookup index of another table", index: other, wantErr: ErrUnknownPath},
+		{name: "Lookup zero index", index: PathTableIndex{}, wantErr: ErrUnknownPath},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := PATHTABLE.LookupFullpath(tt.args.index)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("pathTable.LookupFullpath() error = %v, wantErr %v", err, tt.wantErr)
+			got, err := pt.LookupFullpath(tt.index)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("PathTable.LookupFullpath() error = %v, want %v", err, tt.wantErr)
 				return
 			}
 			if got != tt.want {
-				t.Errorf("pathTable.LookupFullpath() = %v, want %v", got, tt.want)
+				t.Errorf("PathTable.LookupFullpath() = %v, want %v", got, tt.want)
 			}
 		})
 	}
-}
-
-func Test_pathTable_Concurrent_Read(t *testing.T) {
-	// Reset the singleton before the test starts
-	resetPathTable()
-
-	// Store some paths for testing.
-	PATHTABLE.Store(filepath.Join("full1", "path"))
-	PATHTABLE.Store(filepath.Join("full2", "path"))
-	PATHTABLE.Store(filepath.Join("full3", "path"))
 
-	// Number of goroutines to read concurrently
-	numGoroutines := 10
-	var wg sync.WaitGroup
+	// An index knows its path without its table
+	if got, err := other.Fullpath(); err != nil || got != filepath.Join("full1", "path") {
+		t.Errorf("Fullpath() = %v, %v", got, err)
+	}
+}
 
-	// Expected values
+func TestPathTable_Concurrent_Read(t *testing.T) {
+	pt := NewPathTable()
 	expectedPaths := []string{
 		filepath.Join("full1", "path"),
 		filepath.Join("full2", "path"),
 		filepath.Join("full3", "path"),
 	}
+	indices := make([]PathTableIndex, len(expectedPaths))
+	for i, path := range expectedPaths {
+		indices[i] = pt.Store(path)
+	}
 
-	// Run concurrent readers
-	for i := 0; i < numGoroutines; i++ {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
 
-			// Cyclically pick one of the stored indexes (0, 1, or 2)
-			idx := PathTableIndex{index: uint32(i % 3)}
-
-			// Read from the path table
-			localPath, err := PATHTABLE.LookupFullpath(idx)
+			// Cyclically pick one of the stored indices
+			localPath, err := pt.LookupFullpath(indices[i%3])
 			if err != nil {
-				t.Errorf("Error reading path at index %d: %v", idx.index, err)
+				t.Errorf("Error reading path %d: %v", i%3, err)
 				return
 			}
-
-			// Check that the path read matches the expected path
-			expectedPath := expectedPaths[idx.index]
-			if localPath != expectedPath {
-				t.Errorf("Expected path %v, but got %v", expectedPath, localPath)
+			if localPath != expectedPaths[i%3] {
+				t.Errorf("Expected path %v, but got %v", expectedPaths[i%3], localPath)
 			}
 		}(i)
 	}
-
-	// Wait for all goroutines to complete
 	wg.Wait()
 }
+
+func TestPathTable_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "00_traits.txt")
+	writeTree(t, filepath.Dir(path), "00_traits.txt")
+
+	pt := NewPathTable()
+	a, b := pt.File(path, Mod), pt.File(path, Mod)
+	if *a.StoreInPathTable() != *b.StoreInPathTable() {
+		t.Errorf("files of a workspace with the same path have different indices")
+	}
+
+	// Standalone files don't share a table
+	c, d := NewParadoxTxtFile(path, Mod), NewParadoxTxtFile(path, Mod)
+	if *c.StoreInPathTable() == *d.StoreInPathTable() || *c.StoreInPathTable() == *a.StoreInPathTable() {
+		t.Errorf("standalone files share an index")
+	}
+	if pt.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", pt.Len())
+	}
+}
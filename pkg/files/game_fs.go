@@ -0,0 +1,286 @@
+package files
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ModRoot is a mod directory in the load order of a GameFS.
+type ModRoot struct {
+	// Name identifies the mod in messages, usually the name of its descriptor.
+	Name string
+	// Path is the directory of the mod, or where FS comes from if it is set.
+	Path string
+	// FS, if set, holds the files of the mod instead of Path, e.g. a zip archive.
+	FS fs.FS
+	// ReplacePaths are the folders, relative to the game root, whose files from the
+	// game and the mods loaded before this one are ignored. A folder only replaces
+	// its own files, not the files of its subfolders.
+	ReplacePaths []string
+}
+
+// Entry is a file of a GameFS.
+type Entry struct {
+	// Path is the slash-separated path relative to the game root,
+	// e.g. "common/traits/00_traits.txt".
+	Path string
+	// FullPath is the path of the file on disk.
+	FullPath string
+	// Kind tells whether the file comes from the game or a mod.
+	Kind FileKind
+	// Mod is the mod the file comes from, nil for game files.
+	Mod *ModRoot
+
+	// fsys holds the file if it doesn't come from the disk.
+	fsys fs.FS
+}
+
+// File returns the entry as a ParadoxFile, to be parsed. Its locations are shown
+// relative to the game or the mod, see Root.
+func (e *Entry) File() *ParadoxTxtFile {
+	root := e.Root()
+	file := &ParadoxTxtFile{fullpath: e.FullPath, kind: e.Kind, root: &root}
+	if e.fsys != nil {
+		file.source = source{fsys: e.fsys, name: e.Path}
+	}
+	return file
+}
+
+// Root returns the root of the layer the entry comes from.
+func (e *Entry) Root() Root {
+	if e.Mod != nil {
+		return Root{Name: e.Mod.Name, Path: e.Mod.Path}
+	}
+	// The game directory, above the folders of the path
+	dir := e.FullPath
+	for range strings.Count(e.Path, "/") + 1 {
+		dir = filepath.Dir(dir)
+	}
+	return Root{Name: VanillaRootName, Path: dir}
+}
+
+// GameFS overlays the mods on the game files the way the game loads them: a file of a
+// mod replaces the file with the same path in the game and in the mods loaded before.
+type GameFS struct {
+	vanilla string
+	mods    []*ModRoot
+}
+
+// NewGameFS creates a GameFS of the game directory and the mods, in load order.
+// The game directory is the one containing "common", it may be empty to check mods alone.
+func NewGameFS(vanilla string, mods ...*ModRoot) *GameFS {
+	return &GameFS{vanilla: vanilla, mods: mods}
+}
+
+// Mods returns the mods in load order.
+func (g *GameFS) Mods() []*ModRoot {
+	return g.mods
+}
+
+// Resolve returns the file that wins for a path relative to the game root, or an error
+// wrapping fs.ErrNotExist if no layer has it or it is hidden by a replace_path.
+func (g *GameFS) Resolve(name string) (*Entry, error) {
+	name, err := clean(name)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Dir(name)
+
+	for i := len(g.mods) - 1; i >= 0; i-- {
+		mod := g.mods[i]
+		if entry, err := modLayer(mod).stat(name); err != nil || entry != nil {
+			return entry, err
+		}
+		if mod.replaces(dir) {
+			return nil, &fs.PathError{Op: "resolve", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	if g.vanilla != "" {
+		if entry, err := g.vanillaLayer().stat(name); err != nil || entry != nil {
+			return entry, err
+		}
+	}
+	return nil, &fs.PathError{Op: "resolve", Path: name, Err: fs.ErrNotExist}
+}
+
+// Files returns the effective files of a folder and its subfolders, sorted by path.
+// Missing folders have no files.
+func (g *GameFS) Files(folder string) ([]*Entry, error) {
+	folder, err := clean(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*Entry)
+	if g.vanilla != "" {
+		if err := g.vanillaLayer().walk(folder, entries); err != nil {
+			return nil, err
+		}
+	}
+	for _, mod := range g.mods {
+		for name := range entries {
+			if mod.replaces(path.Dir(name)) {
+				delete(entries, name)
+			}
+		}
+		if err := modLayer(mod).walk(folder, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]*Entry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result, nil
+}
+
+// Stack returns every file loaded for a path relative to the game root, in load order:
+// the game file and the files of the mods, from the last mod replacing the folder of
+// the path on. The last one is the file Resolve returns.
+func (g *GameFS) Stack(name string) ([]*Entry, error) {
+	name, err := clean(name)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Dir(name)
+
+	var stack []*Entry
+	for i := len(g.mods) - 1; i >= 0; i-- {
+		mod := g.mods[i]
+		entry, err := modLayer(mod).stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			stack = append(stack, entry)
+		}
+		if mod.replaces(dir) {
+			slices.Reverse(stack)
+			return stack, nil
+		}
+	}
+	if g.vanilla != "" {
+		entry, err := g.vanillaLayer().stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			stack = append(stack, entry)
+		}
+	}
+	slices.Reverse(stack)
+	return stack, nil
+}
+
+// ModFiles returns the files of a folder and its subfolders in every mod, whether they
+// win or not, sorted by path and then in load order.
+func (g *GameFS) ModFiles(folder string) ([]*Entry, error) {
+	folder, err := clean(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Entry
+	for _, mod := range g.mods {
+		entries := make(map[string]*Entry)
+		if err := modLayer(mod).walk(folder, entries); err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			result = append(result, entry)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return slices.Index(g.mods, result[i].Mod) < slices.Index(g.mods, result[j].Mod)
+	})
+	return result, nil
+}
+
+// layer is the game or a mod, read through a file system.
+type layer struct {
+	fsys fs.FS
+	// dir is the directory the full paths of the entries start with.
+	dir  string
+	kind FileKind
+	mod  *ModRoot
+	// onDisk tells whether the full paths are real paths, fsys being os.DirFS(dir).
+	onDisk bool
+}
+
+func (g *GameFS) vanillaLayer() *layer {
+	return &layer{fsys: os.DirFS(g.vanilla), dir: g.vanilla, kind: Vanilla, onDisk: true}
+}
+
+func modLayer(mod *ModRoot) *layer {
+	if mod.FS != nil {
+		return &layer{fsys: mod.FS, dir: mod.Path, kind: Mod, mod: mod}
+	}
+	return &layer{fsys: os.DirFS(mod.Path), dir: mod.Path, kind: Mod, mod: mod, onDisk: true}
+}
+
+func (l *layer) entry(name string) *Entry {
+	entry := &Entry{Path: name, FullPath: filepath.Join(l.dir, filepath.FromSlash(name)), Kind: l.kind, Mod: l.mod}
+	if !l.onDisk {
+		entry.fsys = l.fsys
+	}
+	return entry
+}
+
+// stat returns the entry of a file in the layer, or nil if the layer doesn't have it.
+func (l *layer) stat(name string) (*Entry, error) {
+	info, err := fs.Stat(l.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l.entry(name), nil
+}
+
+// walk adds the files of a folder of the layer to entries, replacing the files of lower layers.
+func (l *layer) walk(folder string, entries map[string]*Entry) error {
+	err := fs.WalkDir(l.fsys, folder, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			entries[name] = l.entry(name)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// replaces reports whether the mod replaces the files of a folder.
+func (m *ModRoot) replaces(dir string) bool {
+	for _, replaced := range m.ReplacePaths {
+		if replaced, err := clean(replaced); err == nil && replaced == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// clean normalizes a path relative to the game root, rejecting paths outside of it.
+func clean(name string) (string, error) {
+	name = path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "/"))
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return "", &fs.PathError{Op: "resolve", Path: name, Err: fs.ErrInvalid}
+	}
+	return name, nil
+}
@@ -0,0 +1,255 @@
+package files
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// writeTree creates the files under root, with their names as content.
+func writeTree(t *testing.T, root string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTestGameFS(t *testing.T) (*GameFS, string, *ModRoot, *ModRoot) {
+	t.Helper()
+	dir := t.TempDir()
+
+	vanilla := filepath.Join(dir, "game")
+	writeTree(t, vanilla,
+		"common/traits/00_traits.txt",
+		"common/traits/01_traits.txt",
+		"common/traits/extra/02_traits.txt",
+		"common/religions/00_religions.txt",
+		"events/00_events.txt",
+	)
+
+	first := &ModRoot{Name: "first", Path: filepath.Join(dir, "first")}
+	writeTree(t, first.Path,
+		"common/traits/01_traits.txt",
+		"common/religions/10_religions.txt",
+	)
+
+	second := &ModRoot{
+		Name:         "second",
+		Path:         filepath.Join(dir, "second"),
+		ReplacePaths: []string{"common/religions"},
+	}
+	writeTree(t, second.Path,
+		"common/traits/01_traits.txt",
+		"common/religions/20_religions.txt",
+	)
+
+	return NewGameFS(vanilla, first, second), vanilla, first, second
+}
+
+func TestGameFS_Resolve(t *testing.T) {
+	gfs, vanilla, _, second := newTestGameFS(t)
+
+	tests := []struct {
+		name     string
+		wantRoot string
+		wantKind FileKind
+		wantErr  error
+	}{
+		{name: "common/traits/00_traits.txt", wantRoot: vanilla, wantKind: Vanilla},
+		{name: "common/traits/01_traits.txt", wantRoot: second.Path, wantKind: Mod},
+		{name: "/common/traits/../traits/01_traits.txt", wantRoot: second.Path, wantKind: Mod},
+		{name: "common/religions/20_religions.txt", wantRoot: second.Path, wantKind: Mod},
+		// Replaced by the second mod
+		{name: "common/religions/00_religions.txt", wantErr: fs.ErrNotExist},
+		{name: "common/religions/10_religions.txt", wantErr: fs.ErrNotExist},
+		{name: "common/traits/missing.txt", wantErr: fs.ErrNotExist},
+		{name: "common/traits", wantErr: fs.ErrNotExist},
+		{name: "../outside.txt", wantErr: fs.ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := gfs.Resolve(tt.name)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			rel, _ := filepath.Rel(tt.wantRoot, entry.FullPath)
+			if entry.Kind != tt.wantKind || filepath.ToSlash(rel) != entry.Path {
+				t.Errorf("resolved to %s (%v), want it under %s", entry.FullPath, entry.Kind, tt.wantRoot)
+			}
+		})
+	}
+}
+
+func TestGameFS_Files(t *testing.T) {
+	gfs, _, first, second := newTestGameFS(t)
+
+	traits, err := gfs.Files("common/traits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		path string
+		mod  *ModRoot
+	}{
+		{"common/traits/00_traits.txt", nil},
+		{"common/traits/01_traits.txt", second},
+		{"common/traits/extra/02_traits.txt", nil},
+	}
+	if len(traits) != len(want) {
+		t.Fatalf("got %d files, want %d", len(traits), len(want))
+	}
+	for i, w := range want {
+		if traits[i].Path != w.path || traits[i].Mod != w.mod {
+			t.Errorf("file %d = %s from %v, want %s from %v", i, traits[i].Path, traits[i].Mod, w.path, w.mod)
+		}
+		if (w.mod == nil) != (traits[i].Kind == Vanilla) {
+			t.Errorf("file %d has kind %v", i, traits[i].Kind)
+		}
+	}
+
+	religions, err := gfs.Files("common/religions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(religions) != 1 || religions[0].Path != "common/religions/20_religions.txt" {
+		t.Errorf("religions = %+v, want only the file of the replacing mod", religions)
+	}
+
+	// The first mod alone doesn't replace anything
+	alone, err := NewGameFS("", first).Files("common")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alone) != 2 {
+		t.Errorf("got %d files without the game, want 2", len(alone))
+	}
+
+	missing, err := gfs.Files("gfx")
+	if err != nil || len(missing) != 0 {
+		t.Errorf("missing folder: got %v, %v", missing, err)
+	}
+}
+
+func TestEntry_Root(t *testing.T) {
+	gfs, vanilla, _, second := newTestGameFS(t)
+
+	tests := []struct {
+		name string
+		want Root
+	}{
+		{name: "common/traits/extra/02_traits.txt", want: Root{Name: VanillaRootName, Path: vanilla}},
+		{name: "events/00_events.txt", want: Root{Name: VanillaRootName, Path: vanilla}},
+		{name: "common/traits/01_traits.txt", want: Root{Name: second.Name, Path: second.Path}},
+	}
+	for _, tt := range tests {
+		entry, err := gfs.Resolve(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := entry.Root(); got != tt.want {
+			t.Errorf("Root() of %s = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if rel, ok := entry.Root().Rel(entry.FullPath); !ok || rel != tt.name {
+			t.Errorf("Rel() of %s = %q, %v", tt.name, rel, ok)
+		}
+	}
+
+	if _, ok := (Root{Path: vanilla}).Rel(second.Path); ok {
+		t.Errorf("a path outside of the root is relative to it")
+	}
+}
+
+func TestGameFS_ModFS(t *testing.T) {
+	gfs, _, _, _ := newTestGameFS(t)
+	mod := &ModRoot{
+		Name: "zipped",
+		Path: "zipped.zip!",
+		FS: fstest.MapFS{
+			"common/traits/00_traits.txt": {Data: []byte("brave = yes")},
+		},
+	}
+	gfs = NewGameFS(gfs.vanilla, append(gfs.Mods(), mod)...)
+
+	entry, err := gfs.Resolve("common/traits/00_traits.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Mod != mod || entry.FullPath != filepath.Join("zipped.zip!", "common", "traits", "00_traits.txt") {
+		t.Errorf("resolved to %s from %v", entry.FullPath, entry.Mod)
+	}
+	content, err := entry.File().ReadFile()
+	if err != nil || string(content) != "brave = yes" {
+		t.Errorf("ReadFile() = %q, %v", content, err)
+	}
+
+	traits, err := gfs.Files("common/traits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traits) != 3 || traits[0].Mod != mod {
+		t.Errorf("files = %+v, want 00_traits.txt from the zipped mod", traits)
+	}
+}
+
+func TestGameFS_Stack(t *testing.T) {
+	gfs, _, first, second := newTestGameFS(t)
+
+	tests := []struct {
+		name string
+		want []*ModRoot
+	}{
+		{name: "common/traits/01_traits.txt", want: []*ModRoot{nil, first, second}},
+		{name: "common/traits/00_traits.txt", want: []*ModRoot{nil}},
+		// The second mod replaces the folder of the game and the first mod
+		{name: "common/religions/20_religions.txt", want: []*ModRoot{second}},
+		{name: "common/religions/00_religions.txt", want: nil},
+	}
+	for _, tt := range tests {
+		stack, err := gfs.Stack(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []*ModRoot
+		for _, entry := range stack {
+			got = append(got, entry.Mod)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Stack(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	modFiles, err := gfs.ModFiles("common")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range modFiles {
+		got = append(got, entry.Mod.Name+":"+entry.Path)
+	}
+	want := []string{
+		"first:common/religions/10_religions.txt",
+		"second:common/religions/20_religions.txt",
+		"first:common/traits/01_traits.txt",
+		"second:common/traits/01_traits.txt",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ModFiles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,230 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Overlay is a file system serving in-memory contents over a base file system, e.g.
+// the unsaved buffers of an editor over the files on disk. It is safe for concurrent use.
+type Overlay struct {
+	base fs.FS
+
+	mu    sync.RWMutex
+	files map[string]*memFileInfo
+}
+
+// NewOverlay creates an Overlay over base, which may be nil for in-memory files only.
+func NewOverlay(base fs.FS) *Overlay {
+	return &Overlay{base: base, files: make(map[string]*memFileInfo)}
+}
+
+// Set replaces the content of a file, or adds it. name is a slash-separated path as
+// accepted by fs.ValidPath.
+func (o *Overlay) Set(name string, content []byte) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "set", Path: name, Err: fs.ErrInvalid}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.files[name] = &memFileInfo{
+		name:    path.Base(name),
+		data:    bytes.Clone(content),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+// Delete drops the in-memory content of a file, the file of the base shows again.
+func (o *Overlay) Delete(name string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.files, name)
+}
+
+// Open opens a file or directory, in memory first, then in the base.
+func (o *Overlay) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	o.mu.RLock()
+	info, ok := o.files[name]
+	o.mu.RUnlock()
+	if ok {
+		return &memFile{info: info, r: bytes.NewReader(info.data)}, nil
+	}
+
+	if o.base != nil {
+		file, err := o.base.Open(name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			// Directories with in-memory files list them too
+			info, err := file.Stat()
+			if err != nil || !info.IsDir() || !o.hasDir(name) {
+				return file, err
+			}
+			file.Close()
+			return &memDir{overlay: o, info: info, name: name}, nil
+		}
+	}
+	if name == "." || o.hasDir(name) {
+		return &memDir{overlay: o, info: &memFileInfo{name: path.Base(name), dir: true}, name: name}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile returns the content of a file, see fs.ReadFileFS.
+func (o *Overlay) ReadFile(name string) ([]byte, error) {
+	o.mu.RLock()
+	info, ok := o.files[name]
+	o.mu.RUnlock()
+	if ok {
+		return bytes.Clone(info.data), nil
+	}
+	if o.base == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return fs.ReadFile(o.base, name)
+}
+
+// ReadDir returns the entries of a directory of the base and the in-memory files under
+// it, sorted by name, see fs.ReadDirFS.
+func (o *Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries := make(map[string]fs.DirEntry)
+
+	var baseErr error
+	if o.base != nil {
+		baseEntries, err := fs.ReadDir(o.base, name)
+		for _, entry := range baseEntries {
+			entries[entry.Name()] = entry
+		}
+		baseErr = err
+	}
+
+	o.mu.RLock()
+	for filename, info := range o.files {
+		rest, ok := under(name, filename)
+		if !ok {
+			continue
+		}
+		if first, _, nested := strings.Cut(rest, "/"); nested {
+			if _, exists := entries[first]; !exists {
+				entries[first] = fs.FileInfoToDirEntry(&memFileInfo{name: first, dir: true})
+			}
+		} else {
+			entries[rest] = fs.FileInfoToDirEntry(info)
+		}
+	}
+	o.mu.RUnlock()
+
+	if len(entries) == 0 && baseErr != nil {
+		return nil, baseErr
+	}
+	result := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result, nil
+}
+
+// hasDir reports whether an in-memory file lies under the directory.
+func (o *Overlay) hasDir(dir string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	for filename := range o.files {
+		if _, ok := under(dir, filename); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// under returns the path of a file relative to a directory, if it lies under it.
+func under(dir, name string) (string, bool) {
+	if dir == "." {
+		return name, true
+	}
+	return strings.CutPrefix(name, dir+"/")
+}
+
+// memFileInfo describes an in-memory file or directory.
+type memFileInfo struct {
+	name    string
+	data    []byte
+	modTime time.Time
+	dir     bool
+}
+
+func (i *memFileInfo) Name() string       { return i.name }
+func (i *memFileInfo) Size() int64        { return int64(len(i.data)) }
+func (i *memFileInfo) ModTime() time.Time { return i.modTime }
+func (i *memFileInfo) IsDir() bool        { return i.dir }
+func (i *memFileInfo) Sys() any           { return nil }
+
+func (i *memFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// memFile is an open in-memory file.
+type memFile struct {
+	info *memFileInfo
+	r    *bytes.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Read(b []byte) (int, error) { return f.r.Read(b) }
+func (f *memFile) Close() error               { return nil }
+
+// memDir is an open directory listing the in-memory files under it.
+type memDir struct {
+	overlay *Overlay
+	info    fs.FileInfo
+	name    string
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.overlay.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.read = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,67 @@
+package files
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOverlay(t *testing.T) {
+	base := fstest.MapFS{
+		"common/traits/00_traits.txt": {Data: []byte("brave = yes")},
+		"events/00_events.txt":        {Data: []byte("namespace = test")},
+	}
+	overlay := NewOverlay(base)
+
+	if err := overlay.Set("common/traits/00_traits.txt", []byte("brave = no")); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.Set("common/religions/00_religions.txt", []byte("catholic = {}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.Set("../outside.txt", nil); err == nil {
+		t.Errorf("expected an error for an invalid path")
+	}
+
+	if content, err := fs.ReadFile(overlay, "common/traits/00_traits.txt"); err != nil || string(content) != "brave = no" {
+		t.Errorf("in-memory file = %q, %v", content, err)
+	}
+	if content, err := fs.ReadFile(overlay, "events/00_events.txt"); err != nil || string(content) != "namespace = test" {
+		t.Errorf("base file = %q, %v", content, err)
+	}
+
+	var names []string
+	err := fs.WalkDir(overlay, "common", func(name string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			names = append(names, name)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"common/religions/00_religions.txt", "common/traits/00_traits.txt"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("walked %v, want %v", names, want)
+	}
+
+	if err := fstest.TestFS(overlay, "common/traits/00_traits.txt", "common/religions/00_religions.txt", "events/00_events.txt"); err != nil {
+		t.Error(err)
+	}
+
+	overlay.Delete("common/traits/00_traits.txt")
+	if content, err := fs.ReadFile(overlay, "common/traits/00_traits.txt"); err != nil || string(content) != "brave = yes" {
+		t.Errorf("after Delete = %q, %v", content, err)
+	}
+}
+
+func TestOverlay_WithoutBase(t *testing.T) {
+	overlay := NewOverlay(nil)
+	if err := overlay.Set("common/traits/00_traits.txt", []byte("brave = yes")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(overlay, "common/traits/00_traits.txt"); err != nil {
+		t.Error(err)
+	}
+}
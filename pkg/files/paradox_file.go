@@ -1,6 +1,8 @@
 package files
 
 import (
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 )
@@ -26,6 +28,7 @@ type ParadoxFile interface {
 	Kind() FileKind                    // Returns whether it's a vanilla or mod file
 	PathIdx() *PathTableIndex          // Returns index in the path table if exists
 	StoreInPathTable() *PathTableIndex // Stores the file in path table and returns its index
+	ReadFile() ([]byte, error)         // Returns the content of the file
 }
 
 type ParadoxTxtFile struct {
@@ -35,6 +38,34 @@ type ParadoxTxtFile struct {
 	kind FileKind
 	// Index into the PathTable (optional, using *PathTableIndex to allow nil)
 	idx *PathTableIndex
+	// The table of the workspace the file belongs to, nil for a standalone file
+	table *PathTable
+	// The root the file was loaded from, nil if unknown
+	root *Root
+	// Where the content is read from
+	source source
+}
+
+// source is where the content of a file is read from: a file system, or the disk if
+// fsys is nil.
+type source struct {
+	fsys fs.FS
+	// name is the path of the file in fsys.
+	name string
+}
+
+func (src source) readFile(fullpath string) ([]byte, error) {
+	if src.fsys == nil {
+		return os.ReadFile(fullpath)
+	}
+	return fs.ReadFile(src.fsys, src.name)
+}
+
+func (src source) stat(fullpath string) (fs.FileInfo, error) {
+	if src.fsys == nil {
+		return os.Stat(fullpath)
+	}
+	return fs.Stat(src.fsys, src.name)
 }
 
 // NewParadoxTxtFile is the constructor for ParadoxFile.
@@ -51,6 +82,40 @@ func NewParadoxTxtFile(fullpath string, kind FileKind) *ParadoxTxtFile {
 	}
 }
 
+// NewParadoxFSFile creates a ParadoxFile read from a file system, e.g. an embed.FS,
+// an Overlay or a zip archive. name is the slash-separated path of the file in fsys,
+// and fullpath the path shown in locations, e.g. "mod.zip!/common/traits/00_traits.txt".
+func NewParadoxFSFile(fsys fs.FS, name, fullpath string, kind FileKind) (*ParadoxTxtFile, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("is a directory")}
+	}
+
+	return &ParadoxTxtFile{
+		fullpath: fullpath,
+		kind:     kind,
+		source:   source{fsys: fsys, name: name},
+	}, nil
+}
+
+// File creates a ParadoxFile stored in this table, so that all the files of a workspace
+// with the same path share an index.
+func (pt *PathTable) File(fullpath string, kind FileKind) *ParadoxTxtFile {
+	file := NewParadoxTxtFile(fullpath, kind)
+	file.table = pt
+	return file
+}
+
+// Add makes a file created elsewhere, e.g. by Entry.File, a file of this table. It has
+// no effect once the file is stored in a path table.
+func (pt *PathTable) Add(file *ParadoxTxtFile) *ParadoxTxtFile {
+	file.table = pt
+	return file
+}
+
 // Kind returns the file kind (vanilla or mod).
 func (file *ParadoxTxtFile) Kind() FileKind {
 	return file.kind
@@ -61,20 +126,47 @@ func (file *ParadoxTxtFile) FullPath() string {
 	return file.fullpath
 }
 
+// ReadFile returns the content of the file, from its file system or the disk, or
+// through the reader of its path table if it has one, see PathTable.SetReader.
+func (file *ParadoxTxtFile) ReadFile() ([]byte, error) {
+	if file.table != nil {
+		if reader := file.table.contentReader(); reader != nil {
+			return reader.ReadFile(*file.StoreInPathTable())
+		}
+	}
+	return file.source.readFile(file.fullpath)
+}
+
 // FileName returns the file name, ensuring it's not empty.
 func (file *ParadoxTxtFile) FileName() string {
 	return filepath.Base(file.fullpath)
 }
 
-// StoreInPathTable stores the file in the PathTable and returns the index.
+// StoreInPathTable stores the file in the PathTable of its workspace and returns the
+// index. A standalone file gets an index of its own.
 func (file *ParadoxTxtFile) StoreInPathTable() *PathTableIndex {
 	if file.idx != nil {
 		return file.idx
 	}
-	file.idx = PATHTABLE.Store(file.fullpath)
+	idx := detached(file.root, file.fullpath, file.source)
+	if file.table != nil {
+		idx = file.table.store(file.root, file.fullpath, file.source)
+	}
+	file.idx = &idx
 	return file.idx
 }
 
+// SetRoot records the root the file was loaded from, for its locations to be shown
+// relative to it. It has no effect once the file is stored in the path table.
+func (file *ParadoxTxtFile) SetRoot(root Root) {
+	file.root = &root
+}
+
+// Root returns the root the file was loaded from, nil if unknown.
+func (file *ParadoxTxtFile) Root() *Root {
+	return file.root
+}
+
 // PathIdx returns the index into the PathTable if it exists, otherwise nil.
 func (file *ParadoxTxtFile) PathIdx() *PathTableIndex {
 	return file.idx
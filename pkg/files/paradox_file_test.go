@@ -1,9 +1,12 @@
 package files
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 )
 
 func TestNewParadoxFile(t *testing.T) {
@@ -61,3 +64,32 @@ func TestParadoxTxtFile_Methods(t *testing.T) {
 		}
 	})
 }
+
+func TestNewParadoxFSFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common/traits/00_traits.txt": {Data: []byte("brave = yes")},
+	}
+
+	file, err := NewParadoxFSFile(fsys, "common/traits/00_traits.txt", "mod.zip!/common/traits/00_traits.txt", Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.FileName() != "00_traits.txt" || file.FullPath() != "mod.zip!/common/traits/00_traits.txt" {
+		t.Errorf("unexpected names %s, %s", file.FileName(), file.FullPath())
+	}
+	content, err := file.ReadFile()
+	if err != nil || string(content) != "brave = yes" {
+		t.Errorf("ReadFile() = %q, %v", content, err)
+	}
+	// Locations read the file through its index, e.g. to show it in diagnostics
+	if content, err := file.StoreInPathTable().ReadFile(); err != nil || string(content) != "brave = yes" {
+		t.Errorf("PathTableIndex.ReadFile() = %q, %v", content, err)
+	}
+
+	if _, err := NewParadoxFSFile(fsys, "common/traits/missing.txt", "missing.txt", Mod); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := NewParadoxFSFile(fsys, "common/traits", "traits", Mod); err == nil {
+		t.Errorf("expected an error for a directory")
+	}
+}
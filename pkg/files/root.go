@@ -0,0 +1,37 @@
+package files
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// VanillaRootName is the name of the root of the game files.
+const VanillaRootName = "vanilla"
+
+// Root is a directory files are loaded from, e.g. the game or a mod. Locations show
+// the files of a root by its name and their path relative to it, so that files with
+// the same path in the game and in a mod can be told apart.
+type Root struct {
+	// Name is shown in brackets before the paths, e.g. "[vanilla]".
+	Name string
+	// Path is the directory of the root.
+	Path string
+}
+
+// Rel returns the slash-separated path of a file relative to the root, and whether
+// the file lies under it.
+func (r Root) Rel(fullpath string) (string, bool) {
+	root, err := filepath.Abs(r.Path)
+	if err != nil {
+		return "", false
+	}
+	fullpath, err = filepath.Abs(fullpath)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, fullpath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,283 @@
+// Package filetypes tells what the game expects of a file from where it lies, e.g.
+// events in events/ or character history in history/characters/: its encoding, what
+// its top-level entries are and which validators apply to it.
+package filetypes
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unLomTrois/gock3/internal/utils"
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+	"github.com/unLomTrois/gock3/pkg/validator"
+)
+
+const (
+	errUnreadFile   = "The game doesn't read %s files from %s, the file has no effect"
+	errInvalidUTF8  = "Invalid UTF-8 byte 0x%02X, the file must be saved as UTF-8"
+	errMissingBOM   = "The file must be saved as UTF-8 with a BOM, the game ignores %s files without one"
+	rootFolderLabel = "the root of the mod"
+)
+
+// tabWidth matches the lexer, which counts a tab as 4 columns.
+const tabWidth = 4
+
+// Encoding is the encoding the game expects a file in.
+type Encoding uint8
+
+const (
+	// UTF8 files may start with a byte order mark or not.
+	UTF8 Encoding = iota
+	// UTF8BOM files must start with a byte order mark.
+	UTF8BOM
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case UTF8:
+		return "utf-8"
+	case UTF8BOM:
+		return "utf-8-bom"
+	default:
+		return "unknown"
+	}
+}
+
+func (e Encoding) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// EntryKind describes the top-level entries of a file.
+type EntryKind uint8
+
+const (
+	// Objects are database entries keyed by their names, e.g. "brave = { ... }".
+	Objects EntryKind = iota
+	// Events are namespace declarations and events keyed by "<namespace>.<number>".
+	Events
+	// History entries are keyed by ids, e.g. of characters, and hold dated blocks.
+	History
+	// Localization files map keys to texts under a "l_<language>:" header.
+	Localization
+	// Widgets are the windows and types of the interface.
+	Widgets
+)
+
+func (k EntryKind) String() string {
+	switch k {
+	case Objects:
+		return "objects"
+	case Events:
+		return "events"
+	case History:
+		return "history"
+	case Localization:
+		return "localization"
+	case Widgets:
+		return "widgets"
+	default:
+		return "unknown"
+	}
+}
+
+func (k EntryKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// FileType describes the files of a folder and its subfolders.
+type FileType struct {
+	Name string
+	// Folder is the slash-separated folder, relative to the game root, whose files have
+	// the type. Its parts may be patterns as accepted by path.Match, e.g. "common/*".
+	Folder string
+	// Ext is the extension of the files, e.g. ".txt".
+	Ext      string
+	Encoding Encoding
+	Entries  EntryKind
+	// IdentityKey is the field naming an entry, empty if entries are named by their key.
+	IdentityKey string
+	// Policies declares the duplicate-key semantics of the files, nil if unknown.
+	Policies *ast.Policies
+	// Ignored tells that the game doesn't read the files.
+	Ignored bool
+}
+
+// matches reports whether a file, by its path relative to the game root, has the type.
+func (ft *FileType) matches(name string) bool {
+	if !strings.EqualFold(path.Ext(name), ft.Ext) {
+		return false
+	}
+	dir := strings.Split(path.Dir(name), "/")
+	folder := strings.Split(ft.Folder, "/")
+	if len(dir) < len(folder) {
+		return false
+	}
+	for i, pattern := range folder {
+		if ok, _ := path.Match(pattern, dir[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Registry finds the type of a file by its path.
+type Registry struct {
+	types []*FileType
+}
+
+// NewRegistry creates a Registry of the types, tried in order: the first one matching
+// a file is its type, so specific folders come before the folders containing them.
+func NewRegistry(types ...*FileType) *Registry {
+	return &Registry{types: types}
+}
+
+// Default is the registry of the folders of Crusader Kings III.
+var Default = NewRegistry(
+	&FileType{Name: "on_actions", Folder: "common/on_action", Ext: ".txt", Entries: Objects},
+	&FileType{Name: "defines", Folder: "common/defines", Ext: ".txt", Entries: Objects},
+	&FileType{Name: "database", Folder: "common/*", Ext: ".txt", Entries: Objects},
+	// The game only reads the subfolders of common and history
+	&FileType{Name: "common", Folder: "common", Ext: ".txt", Ignored: true},
+	&FileType{Name: "events", Folder: "events", Ext: ".txt", Entries: Events, Policies: validator.Events},
+	&FileType{
+		Name:     "character history",
+		Folder:   "history/characters",
+		Ext:      ".txt",
+		Entries:  History,
+		Policies: validator.CharacterHistory,
+	},
+	&FileType{Name: "history", Folder: "history/*", Ext: ".txt", Entries: History},
+	&FileType{Name: "history", Folder: "history", Ext: ".txt", Ignored: true},
+	&FileType{Name: "localization", Folder: "localization", Ext: ".yml", Encoding: UTF8BOM, Entries: Localization},
+	&FileType{Name: "interface", Folder: "gui", Ext: ".gui", Entries: Widgets, IdentityKey: "name"},
+)
+
+// Lookup returns the type of a file by its slash-separated path relative to the game
+// or mod root, e.g. "common/traits/00_traits.txt".
+func (r *Registry) Lookup(name string) (*FileType, bool) {
+	name = path.Clean(name)
+	for _, ft := range r.types {
+		if ft.matches(name) {
+			return ft, true
+		}
+	}
+	return nil, false
+}
+
+// Read reports whether the game reads a file. Files of an unknown type are read if
+// they lie in a folder of the game, as they may be read by a type missing from the
+// registry, and files with an extension of no type are always read.
+func (r *Registry) Read(name string) bool {
+	name = path.Clean(name)
+	if ft, ok := r.Lookup(name); ok {
+		return !ft.Ignored
+	}
+	if !r.knownExt(path.Ext(name)) {
+		return true
+	}
+	top, _, nested := strings.Cut(name, "/")
+	return nested && files.IsGameFolder(top)
+}
+
+func (r *Registry) knownExt(ext string) bool {
+	for _, ft := range r.types {
+		if strings.EqualFold(ft.Ext, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check runs the checks of the type of a file, found by its path relative to the game
+// or mod root: whether the game reads it, its encoding and the validators of the type.
+// The error is only set if the file can't be read.
+func (r *Registry) Check(file files.ParadoxFile, name string, tree *ast.AST) ([]*report.DiagnosticItem, error) {
+	diagnostics, err := r.CheckContent(file, name, tree)
+	if err != nil || r.Read(name) {
+		return diagnostics, err
+	}
+
+	folder := path.Dir(path.Clean(name))
+	if folder == "." {
+		folder = rootFolderLabel
+	}
+	loc := tokens.LocFromParadoxFile(file)
+	errMsg := fmt.Sprintf(errUnreadFile, path.Ext(name), folder)
+	return append([]*report.DiagnosticItem{report.FromLoc(*loc, codes.UnreadFile, errMsg)}, diagnostics...), nil
+}
+
+// CheckContent runs the checks of Check but whether the game reads the file, for files
+// whose root may not be a mod, e.g. a directory holding a single file.
+func (r *Registry) CheckContent(file files.ParadoxFile, name string, tree *ast.AST) ([]*report.DiagnosticItem, error) {
+	content, err := file.ReadFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []*report.DiagnosticItem
+	ft, ok := r.Lookup(name)
+	encoding := UTF8
+	if ok {
+		encoding = ft.Encoding
+	}
+	if diag := checkEncoding(file, content, encoding); diag != nil {
+		diagnostics = append(diagnostics, diag)
+	}
+
+	if ok && ft.Policies != nil && tree != nil {
+		diagnostics = append(diagnostics, validator.DuplicateKeys(tree.Block, ft.Policies)...)
+	}
+	return diagnostics, nil
+}
+
+// Codes returns the codes of the diagnostics CheckContent reports for a file, by its
+// path relative to the game or mod root. Check also reports codes.UnreadFile.
+func (r *Registry) Codes(name string) []codes.Code {
+	result := []codes.Code{codes.WrongEncoding}
+	if ft, ok := r.Lookup(name); ok && ft.Policies != nil {
+		result = append(result, codes.RepeatedKey)
+	}
+	return result
+}
+
+// checkEncoding reports the first byte that isn't valid UTF-8, or a missing byte order mark.
+func checkEncoding(file files.ParadoxFile, content []byte, encoding Encoding) *report.DiagnosticItem {
+	loc := tokens.LocFromParadoxFile(file)
+
+	text := utils.TrimUTF8BOM(content)
+	if encoding == UTF8BOM && len(text) == len(content) {
+		return report.FromLoc(*loc, codes.WrongEncoding, fmt.Sprintf(errMissingBOM, path.Ext(file.FileName())))
+	}
+	if utf8.Valid(text) {
+		return nil
+	}
+
+	for offset := 0; offset < len(text); {
+		r, size := utf8.DecodeRune(text[offset:])
+		if r == utf8.RuneError && size <= 1 {
+			// Columns count bytes and tabs as tabWidth, as for tokens
+			for _, b := range text[:offset] {
+				switch b {
+				case '\n':
+					loc.Line++
+					loc.Column = 1
+				case '\t':
+					loc.Column += tabWidth
+				default:
+					loc.Column++
+				}
+			}
+			diag := report.FromLoc(*loc, codes.WrongEncoding, fmt.Sprintf(errInvalidUTF8, text[offset]))
+			diag.Pointer.Length = 1
+			return diag
+		}
+		offset += size
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package filetypes_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/filetypes"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+)
+
+func TestRegistry_Lookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantType string
+		wantRead bool
+	}{
+		{name: "common/traits/00_traits.txt", wantType: "database", wantRead: true},
+		{name: "common/coat_of_arms/coat_of_arms/00_titles.txt", wantType: "database", wantRead: true},
+		{name: "common/on_action/00_on_actions.txt", wantType: "on_actions", wantRead: true},
+		{name: "common/00_traits.txt", wantType: "common", wantRead: false},
+		{name: "events/travel/00_events.TXT", wantType: "events", wantRead: true},
+		{name: "history/characters/castilian.txt", wantType: "character history", wantRead: true},
+		{name: "history/titles/k_castille.txt", wantType: "history", wantRead: true},
+		{name: "history/00_history.txt", wantType: "history", wantRead: false},
+		{name: "localization/english/traits_l_english.yml", wantType: "localization", wantRead: true},
+		// Files of unknown types are read in the folders of the game only
+		{name: "gfx/portraits/portrait_modifiers/00_hair.txt", wantRead: true},
+		{name: "comon/traits/00_traits.txt", wantRead: false},
+		{name: "00_traits.txt", wantRead: false},
+		{name: "thumbnail.png", wantRead: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if ft, ok := filetypes.Default.Lookup(tt.name); ok {
+				got = ft.Name
+			}
+			if got != tt.wantType {
+				t.Errorf("Lookup() = %q, want %q", got, tt.wantType)
+			}
+			if got := filetypes.Default.Read(tt.name); got != tt.wantRead {
+				t.Errorf("Read() = %v, want %v", got, tt.wantRead)
+			}
+		})
+	}
+}
+
+func TestRegistry_Check(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantCodes []codes.Code
+	}{
+		{name: "common/traits/00_traits.txt", content: "brave = { }\n"},
+		{name: "common/traits/00_traits.txt", content: "\xef\xbb\xbfbrave = { }\n"},
+		{name: "common/00_traits.txt", content: "brave = { }\n", wantCodes: []codes.Code{codes.UnreadFile}},
+		{name: "common/traits/00_traits.txt", content: "brave = {\n\tname = \"Caf\xe9\"\n}\n", wantCodes: []codes.Code{codes.WrongEncoding}},
+		{name: "localization/english/traits_l_english.yml", content: "l_english:\n", wantCodes: []codes.Code{codes.WrongEncoding}},
+		{name: "localization/english/traits_l_english.yml", content: "\xef\xbb\xbfl_english:\n"},
+		// The validators of the type run on the file
+		{name: "history/characters/castilian.txt", content: "70027 = {\n\tname = Eric\n\tname = Erik\n}\n", wantCodes: []codes.Code{codes.RepeatedKey}},
+		{name: "common/traits/00_traits.txt", content: "brave = {\n\tname = Eric\n\tname = Erik\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), filepath.Base(tt.name))
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			file := files.NewParadoxTxtFile(path, files.Mod)
+			tree, _, err := parser.ParseParadoxFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diagnostics, err := filetypes.Default.Check(file, tt.name, tree)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []codes.Code
+			for _, diag := range diagnostics {
+				got = append(got, diag.Code)
+			}
+			if len(got) != len(tt.wantCodes) {
+				t.Fatalf("Check() = %v, want %v", got, tt.wantCodes)
+			}
+			for i := range got {
+				if got[i] != tt.wantCodes[i] {
+					t.Errorf("Check() = %v, want %v", got, tt.wantCodes)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry_CheckContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "00_traits.txt")
+	if err := os.WriteFile(path, []byte("brave = {\n\tname = \"Caf\xe9\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Whether the game reads the file isn't checked
+	diagnostics, err := filetypes.Default.CheckContent(files.NewParadoxTxtFile(path, files.Mod), "00_traits.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Code != codes.WrongEncoding {
+		t.Errorf("CheckContent() = %v, want a single %s", diagnostics, codes.WrongEncoding)
+	}
+}
+
+func TestRegistry_Check_Position(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantLine   uint32
+		wantColumn uint16
+	}{
+		{name: "after a BOM", content: "\xef\xbb\xbfbrave = {\n\tname = \"Caf\xe9\"\n}\n", wantLine: 2, wantColumn: 16},
+		// Tabs count as 4 columns, as for tokens
+		{name: "after tabs", content: "a = {\n\t\tb = \"x\xff\"\n}\n", wantLine: 2, wantColumn: 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "00_traits.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			diagnostics, err := filetypes.Default.Check(files.NewParadoxTxtFile(path, files.Mod), "common/traits/00_traits.txt", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(diagnostics) != 1 {
+				t.Fatalf("got %d diagnostics, want 1", len(diagnostics))
+			}
+			if loc := diagnostics[0].Pointer.Loc; loc.Line != tt.wantLine || loc.Column != tt.wantColumn {
+				t.Errorf("invalid byte at %d:%d, want %d:%d", loc.Line, loc.Column, tt.wantLine, tt.wantColumn)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package fix
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	// a and b are the line indices in the old and new text.
+	a, b int
+}
+
+// UnifiedDiff writes the differences between old and new as a unified diff, the
+// format read by patch and git apply. Nothing is written if the contents are equal.
+func UnifiedDiff(w io.Writer, oldName, newName string, old, new []byte) error {
+	a, b := splitLines(string(old)), splitLines(string(new))
+	ops := diffLines(a, b)
+
+	hunks := groupHunks(ops)
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", oldName, newName); err != nil {
+		return err
+	}
+	for _, hunk := range hunks {
+		if err := writeHunk(w, hunk, a, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHunk(w io.Writer, hunk []op, a, b []string) error {
+	aStart, bStart := hunk[0].a, hunk[0].b
+	aCount, bCount := 0, 0
+	for _, o := range hunk {
+		if o.kind != opInsert {
+			aCount++
+		}
+		if o.kind != opDelete {
+			bCount++
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount)); err != nil {
+		return err
+	}
+	for _, o := range hunk {
+		line := ""
+		switch o.kind {
+		case opInsert:
+			line = b[o.b]
+		default:
+			line = a[o.a]
+		}
+		if _, err := fmt.Fprintf(w, "%c%s", o.kind, line); err != nil {
+			return err
+		}
+		if !strings.HasSuffix(line, "\n") {
+			if _, err := io.WriteString(w, "\n\\ No newline at end of file\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hunkRange formats the start and length of a hunk, 1-based as patch expects.
+// An empty range starts at the line before it.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// groupHunks splits the edit script into hunks of changes with their context.
+func groupHunks(ops []op) [][]op {
+	var hunks [][]op
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		start := max(i-contextLines, 0)
+		// Extend the hunk while the next change is close enough to share context
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == opEqual {
+				next++
+			}
+			if next == len(ops) || next-end > 2*contextLines {
+				end = min(end+contextLines, len(ops))
+				break
+			}
+			end = next
+		}
+
+		hunks = append(hunks, ops[start:end])
+		i = end
+	}
+	return hunks
+}
+
+// diffLines computes the shortest edit script from a to b with Myers' algorithm.
+func diffLines(a, b []string) []op {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+2)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int{}, v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset, d)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the saved frontiers back from the end to recover the edit script.
+func backtrack(trace [][]int, a, b []string, offset, d int) []op {
+	x, y := len(a), len(b)
+	var ops []op
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{kind: opEqual, a: x, b: y})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, op{kind: opInsert, a: x, b: y})
+		} else {
+			x--
+			ops = append(ops, op{kind: opDelete, a: x, b: y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, op{kind: opEqual, a: x, b: y})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// splitLines splits text into lines, keeping the line breaks.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
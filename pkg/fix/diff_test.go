@@ -0,0 +1,59 @@
+package fix_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/fix"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a = b\n",
+			new:  "a = b\n",
+			want: "",
+		},
+		{
+			name: "changed line with context",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			new:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name: "appended line without newline at end of file",
+			old:  "a = {\n\tb = c",
+			new:  "a = {\n\tb = c\n}",
+			want: "--- a\n+++ b\n@@ -1,2 +1,3 @@\n a = {\n-\tb = c\n\\ No newline at end of file\n+\tb = c\n+}\n\\ No newline at end of file\n",
+		},
+		{
+			name: "insertion into an empty file",
+			old:  "",
+			new:  "a = b\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+a = b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := fix.UnifiedDiff(&buf, "a", "b", []byte(tt.old), []byte(tt.new)); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Package fix applies the machine-applicable fixes carried by diagnostics to the
+// content of files.
+package fix
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// tabWidth matches the lexer, which counts a tab as 4 columns.
+const tabWidth = 4
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Result is the outcome of applying fixes to a file.
+type Result struct {
+	Content []byte
+	// Applied are the fixes whose edits were made.
+	Applied []*report.Fix
+	// Skipped are the fixes that overlap an applied fix or point outside the file.
+	Skipped []*report.Fix
+}
+
+// span is an edit resolved to byte offsets.
+type span struct {
+	start, end int
+	text       string
+	order      int
+}
+
+// Apply applies the fixes to content, the raw content of the file the fixes were
+// computed for. Fixes are taken in order of their first edit; a fix overlapping an
+// already accepted one is skipped as a whole, to be applied by a later run.
+// Insertions at the same offset are kept in the order of their fixes.
+func Apply(content []byte, fixes []*report.Fix) *Result {
+	// Locations are computed on the text without the byte order mark
+	base := 0
+	if bytes.HasPrefix(content, utf8BOM) {
+		base = len(utf8BOM)
+	}
+	lineStarts := lineOffsets(content, base)
+
+	type candidate struct {
+		fix   *report.Fix
+		spans []span
+	}
+
+	result := &Result{}
+	var candidates []candidate
+	for _, fix := range fixes {
+		spans, ok := resolve(content, lineStarts, fix.Edits)
+		if !ok || len(spans) == 0 {
+			result.Skipped = append(result.Skipped, fix)
+			continue
+		}
+		candidates = append(candidates, candidate{fix: fix, spans: spans})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].spans[0].start < candidates[j].spans[0].start
+	})
+
+	var accepted []span
+	for _, c := range candidates {
+		if overlapsAny(c.spans, accepted) {
+			result.Skipped = append(result.Skipped, c.fix)
+			continue
+		}
+		for _, s := range c.spans {
+			s.order = len(accepted)
+			accepted = append(accepted, s)
+		}
+		result.Applied = append(result.Applied, c.fix)
+	}
+
+	// Apply from the end of the file, so earlier offsets stay valid
+	sort.SliceStable(accepted, func(i, j int) bool {
+		if accepted[i].start != accepted[j].start {
+			return accepted[i].start > accepted[j].start
+		}
+		return accepted[i].order > accepted[j].order
+	})
+
+	out := append([]byte{}, content...)
+	for _, s := range accepted {
+		out = append(out[:s.start], append([]byte(s.text), out[s.end:]...)...)
+	}
+	result.Content = out
+	return result
+}
+
+// resolve converts edits to byte offsets, sorted by start.
+func resolve(content []byte, lineStarts []int, edits []*report.Edit) ([]span, bool) {
+	spans := make([]span, 0, len(edits))
+	for _, edit := range edits {
+		start, ok := offset(content, lineStarts, edit.Loc)
+		if !ok || edit.Length < 0 || start+edit.Length > len(content) {
+			return nil, false
+		}
+		spans = append(spans, span{start: start, end: start + edit.Length, text: edit.Text})
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	for i := 1; i < len(spans); i++ {
+		if overlaps(spans[i-1], spans[i]) {
+			return nil, false
+		}
+	}
+	return spans, true
+}
+
+// lineOffsets returns the offset of the first byte of every line.
+func lineOffsets(content []byte, base int) []int {
+	starts := []int{base}
+	for i := base; i < len(content); i++ {
+		if content[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// offset converts a line and column, counted the way the lexer does, to a byte offset.
+func offset(content []byte, lineStarts []int, loc tokens.Loc) (int, bool) {
+	if loc.Line == 0 || int(loc.Line) > len(lineStarts) {
+		return 0, false
+	}
+
+	i := lineStarts[loc.Line-1]
+	column := 1
+	for column < int(loc.Column) {
+		if i >= len(content) || content[i] == '\n' || content[i] == '\r' {
+			// Past the end of the line, e.g. end-of-file insertions
+			return i, column+1 == int(loc.Column) || i >= len(content)
+		}
+		if content[i] == '\t' {
+			column += tabWidth
+		} else {
+			column++
+		}
+		i++
+	}
+	return i, column == int(loc.Column)
+}
+
+func overlaps(a, b span) bool {
+	if a.start == a.end || b.start == b.end {
+		// An insertion only conflicts with a replacement strictly around it
+		return (a.start == a.end && b.start < a.start && a.start < b.end) ||
+			(b.start == b.end && a.start < b.start && b.start < a.end)
+	}
+	return a.start < b.end && b.start < a.end
+}
+
+func overlapsAny(spans, accepted []span) bool {
+	for _, s := range spans {
+		for _, a := range accepted {
+			if overlaps(s, a) {
+				return true
+			}
+		}
+	}
+	return false
+}
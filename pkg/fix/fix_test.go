@@ -0,0 +1,126 @@
+package fix_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/fix"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+	"github.com/unLomTrois/gock3/pkg/validator"
+)
+
+// fixesOf parses content and returns the fixes of its diagnostics.
+func fixesOf(t *testing.T, content string, renames map[string]string) []*report.Fix {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tree, diagnostics, err := parser.ParseParadoxFile(files.NewParadoxTxtFile(path, files.Mod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diagnostics = append(diagnostics, validator.DeprecatedKeys(tree.Block, renames)...)
+
+	var fixes []*report.Fix
+	for _, diag := range diagnostics {
+		fixes = append(fixes, diag.Fixes...)
+	}
+	return fixes
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		renames map[string]string
+		want    string
+	}{
+		{
+			name:    "missing closing brace",
+			content: "brave = {\n\tcategory = personality\n",
+			want:    "brave = {\n\tcategory = personality\n}\n",
+		},
+		{
+			name:    "missing closing braces of nested blocks",
+			content: "brave = {\n\tmodifier = {\n\t\tmonthly_prestige = 1",
+			want:    "brave = {\n\tmodifier = {\n\t\tmonthly_prestige = 1\n\t}\n}",
+		},
+		{
+			name:    "smart quotes",
+			content: "name = “Erik”\n",
+			want:    "name = \"Erik\"\n",
+		},
+		{
+			name:    "comparison used as assignment",
+			content: "is_valid == {\n\tis_adult == yes\n\tage == 16\n}\n",
+			want:    "is_valid = {\n\tis_adult = yes\n\tage == 16\n}\n",
+		},
+		{
+			name:    "deprecated key after a tab",
+			content: "trait = {\n\t\told_key = yes\n}\n",
+			renames: map[string]string{"old_key": "new_key"},
+			want:    "trait = {\n\t\tnew_key = yes\n}\n",
+		},
+		{
+			name:    "CRLF line endings",
+			content: "a = {\r\n\tb == yes\r\n}\r\nc = “d”\r\n",
+			want:    "a = {\r\n\tb = yes\r\n}\r\nc = \"d\"\r\n",
+		},
+		{
+			name:    "byte order mark",
+			content: "\xEF\xBB\xBFname = “Erik”\n",
+			want:    "\xEF\xBB\xBFname = \"Erik\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixes := fixesOf(t, tt.content, tt.renames)
+			result := fix.Apply([]byte(tt.content), fixes)
+			if got := string(result.Content); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if len(result.Skipped) != 0 {
+				t.Errorf("skipped %d fixes", len(result.Skipped))
+			}
+			// Fixed content has nothing left to fix
+			if again := fixesOf(t, tt.want, tt.renames); len(again) != 0 {
+				t.Errorf("fixed content still has %d fixes", len(again))
+			}
+		})
+	}
+}
+
+func TestApply_Overlapping(t *testing.T) {
+	content := []byte("key = value\n")
+	at := func(column uint16) tokens.Loc {
+		return tokens.Loc{Line: 1, Column: column}
+	}
+
+	first := &report.Fix{Edits: []*report.Edit{{Loc: at(1), Length: 3, Text: "new_key"}}}
+	overlapping := &report.Fix{Edits: []*report.Edit{
+		{Loc: at(7), Length: 5, Text: "other"},
+		{Loc: at(2), Length: 1, Text: "E"},
+	}}
+	insertion := &report.Fix{Edits: []*report.Edit{{Loc: at(12), Text: " # done"}}}
+	outside := &report.Fix{Edits: []*report.Edit{{Loc: at(40), Length: 1, Text: "x"}}}
+
+	result := fix.Apply(content, []*report.Fix{first, overlapping, insertion, outside})
+	if got, want := string(result.Content), "new_key = value # done\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(result.Applied) != 2 || len(result.Skipped) != 2 {
+		t.Errorf("applied %d and skipped %d fixes, want 2 and 2", len(result.Applied), len(result.Skipped))
+	}
+	// The overlapping fix is skipped as a whole
+	if bytes.Contains(result.Content, []byte("other")) {
+		t.Errorf("edits of a skipped fix were applied")
+	}
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"slices"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+	"github.com/unLomTrois/gock3/pkg/values"
+)
+
+// State is the effective state of a history entry at a date.
+type State struct {
+	ID   string      `json:"id"`
+	Date values.Date `json:"date"`
+	Name string      `json:"name,omitempty"`
+
+	Born  bool         `json:"born"`
+	Birth *values.Date `json:"birth,omitempty"`
+	Dead  bool         `json:"dead"`
+	Death *values.Date `json:"death,omitempty"`
+
+	Traits     []string `json:"traits"`
+	Capital    string   `json:"capital,omitempty"`
+	Government string   `json:"government,omitempty"`
+
+	// Fields holds the latest value of every other literal field, such as culture or faith.
+	Fields map[string]string `json:"fields"`
+}
+
+func newState(id string, date values.Date) *State {
+	return &State{
+		ID:     id,
+		Date:   date,
+		Traits: []string{},
+		Fields: make(map[string]string),
+	}
+}
+
+// Alive reports whether the entry has been born and has not died yet.
+func (s *State) Alive() bool {
+	return s.Born && !s.Dead
+}
+
+// apply applies the fields of an event dated at date, or of the base block if date is
+// nil. Birth and death are only recorded in dated events, as the base block has no date.
+func (s *State) apply(fields []*ast.Field, date *values.Date) {
+	for _, field := range fields {
+		key := field.Key.Value
+		token, isLiteral := field.Value.(*tokens.Token)
+
+		switch key {
+		case "birth":
+			if date != nil {
+				s.Born, s.Birth = true, date
+			}
+		case "death":
+			// Either "death = yes" or "death = { death_reason = ... }".
+			if date == nil || isLiteral && token.Value == "no" {
+				continue
+			}
+			s.Dead, s.Death = true, date
+		case "trait", "add_trait":
+			if isLiteral {
+				s.addTrait(token.Value)
+			}
+		case "remove_trait":
+			if isLiteral {
+				s.removeTrait(token.Value)
+			}
+		case "capital":
+			if isLiteral {
+				s.Capital = token.Value
+			}
+		case "government", "change_government":
+			if isLiteral {
+				s.Government = token.Value
+			}
+		case "name":
+			if isLiteral {
+				s.Name = token.Value
+			}
+		case "effect":
+			// Effects may change traits and government as well.
+			if block, ok := field.Value.(*ast.FieldBlock); ok {
+				s.applyEffect(block.Values)
+			}
+		default:
+			if isLiteral {
+				s.Fields[key] = token.Value
+			}
+		}
+	}
+}
+
+// applyEffect applies the effects inside an "effect = { ... }" block that change the state.
+func (s *State) applyEffect(fields []*ast.Field) {
+	for _, field := range fields {
+		token, ok := field.Value.(*tokens.Token)
+		if !ok {
+			continue
+		}
+		switch field.Key.Value {
+		case "add_trait":
+			s.addTrait(token.Value)
+		case "remove_trait":
+			s.removeTrait(token.Value)
+		case "change_government":
+			s.Government = token.Value
+		}
+	}
+}
+
+func (s *State) addTrait(trait string) {
+	if !slices.Contains(s.Traits, trait) {
+		s.Traits = append(s.Traits, trait)
+	}
+}
+
+func (s *State) removeTrait(trait string) {
+	s.Traits = slices.DeleteFunc(s.Traits, func(t string) bool {
+		return t == trait
+	})
+}
@@ -0,0 +1,52 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteText writes a human-readable description of the state.
+func (s *State) WriteText(w io.Writer) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s at %s\n", s.ID, s.Date)
+	writeLine(&sb, "name", s.Name)
+	writeLine(&sb, "status", s.status())
+	writeLine(&sb, "traits", strings.Join(s.Traits, ", "))
+	writeLine(&sb, "capital", s.Capital)
+	writeLine(&sb, "government", s.Government)
+
+	keys := make([]string, 0, len(s.Fields))
+	for key := range s.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeLine(&sb, key, s.Fields[key])
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (s *State) status() string {
+	switch {
+	case s.Dead && s.Born:
+		return fmt.Sprintf("dead (born %s, died %s)", s.Birth, s.Death)
+	case s.Dead:
+		return fmt.Sprintf("dead (died %s)", s.Death)
+	case s.Born:
+		return fmt.Sprintf("alive (born %s)", s.Birth)
+	default:
+		return "not born yet"
+	}
+}
+
+func writeLine(sb *strings.Builder, label, value string) {
+	if value == "" {
+		value = "-"
+	}
+	fmt.Fprintf(sb, "  %-12s %s\n", label+":", value)
+}
@@ -0,0 +1,101 @@
+// Package history interprets history entries, such as characters in
+// history/characters, as timelines and resolves their state at a given date.
+package history
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+	"github.com/unLomTrois/gock3/pkg/validator"
+	"github.com/unLomTrois/gock3/pkg/values"
+)
+
+// Event is a dated block of a history entry, e.g. "941.1.1 = { birth = yes }".
+type Event struct {
+	Date  values.Date
+	Block *ast.FieldBlock
+}
+
+// Timeline is a history entry split into its undated base fields and its dated events.
+type Timeline struct {
+	ID     string
+	Base   []*ast.Field
+	Events []*Event
+}
+
+// NewTimeline builds the timeline of a history entry such as "70027 = { ... }".
+// Events are ordered by date; events sharing a date keep their order in the file.
+// Dated keys that are not valid dates are reported and skipped.
+func NewTimeline(entry *ast.Field) (*Timeline, []*report.DiagnosticItem) {
+	timeline := &Timeline{ID: entry.Key.Value}
+	var diagnostics []*report.DiagnosticItem
+
+	block, ok := entry.Value.(*ast.FieldBlock)
+	if !ok {
+		return timeline, diagnostics
+	}
+
+	for _, field := range block.Values {
+		if field.Key.Type != tokens.DATE {
+			timeline.Base = append(timeline.Base, field)
+			continue
+		}
+
+		date, err := field.Key.DateValue()
+		if err != nil {
+			diag := report.FromToken(field.Key, codes.InvalidHistoryDate, fmt.Sprintf("Invalid history date: %s", err))
+			diagnostics = append(diagnostics, diag)
+			continue
+		}
+
+		if eventBlock, ok := field.Value.(*ast.FieldBlock); ok {
+			timeline.Events = append(timeline.Events, &Event{Date: date, Block: eventBlock})
+		}
+	}
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		return timeline.Events[i].Date.Before(timeline.Events[j].Date)
+	})
+
+	return timeline, diagnostics
+}
+
+// Find returns the timeline of the top-level entry with the given id. If the id is
+// defined more than once, the definition that takes effect in game is used.
+func Find(tree *ast.AST, id string) (*Timeline, []*report.DiagnosticItem, error) {
+	if tree.Block != nil {
+		if field := tree.Block.Lookup(id, validator.CharacterHistory); field != nil {
+			timeline, diagnostics := NewTimeline(field)
+			return timeline, diagnostics, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no history entry %q in %s", id, tree.Filename)
+}
+
+// At resolves the state of the entry at the given date: the base fields are
+// applied first, followed by every event dated on or before it.
+func (t *Timeline) At(date values.Date) *State {
+	state := newState(t.ID, date)
+	state.apply(t.Base, nil)
+
+	for _, event := range t.Events {
+		if event.Date.After(date) {
+			break
+		}
+		eventDate := event.Date
+		state.apply(event.Block.Values, &eventDate)
+	}
+	return state
+}
+
+// Latest resolves the state of the entry after all of its events.
+func (t *Timeline) Latest() *State {
+	if len(t.Events) == 0 {
+		return t.At(values.Date{})
+	}
+	return t.At(t.Events[len(t.Events)-1].Date)
+}
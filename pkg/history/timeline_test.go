@@ -0,0 +1,153 @@
+package history_test
+
+import (
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/testutil"
+	"github.com/unLomTrois/gock3/pkg/history"
+	"github.com/unLomTrois/gock3/pkg/values"
+)
+
+const character = `70027 = {
+	name = "Teresa"
+	culture = castilian
+	trait = education_learning_2
+
+	997.1.1 = {
+		death = yes
+	}
+	941.1.1 = {
+		birth = yes
+	}
+	966.11.15 = {
+		trait = devoted
+		capital = c_burgos
+	}
+	980.1.1 = {
+		effect = {
+			remove_trait = devoted
+			change_government = feudal_government
+		}
+		culture = leonese
+	}
+}
+`
+
+func date(t *testing.T, s string) values.Date {
+	t.Helper()
+	d, err := values.ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestTimeline_At(t *testing.T) {
+	timeline, diagnostics, err := history.Find(testutil.ParseString(t, character), "70027")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(diagnostics) != 0 {
+		t.Fatalf("Find() returned %d diagnostics, want 0", len(diagnostics))
+	}
+
+	tests := []struct {
+		at         string
+		alive      bool
+		traits     []string
+		capital    string
+		government string
+		culture    string
+	}{
+		{at: "900.1.1", alive: false, traits: []string{"education_learning_2"}, culture: "castilian"},
+		{at: "941.1.1", alive: true, traits: []string{"education_learning_2"}, culture: "castilian"},
+		{at: "970.6.1", alive: true, traits: []string{"education_learning_2", "devoted"}, capital: "c_burgos", culture: "castilian"},
+		{at: "980.1.1", alive: true, traits: []string{"education_learning_2"}, capital: "c_burgos", government: "feudal_government", culture: "leonese"},
+		{at: "1066.9.15", alive: false, traits: []string{"education_learning_2"}, capital: "c_burgos", government: "feudal_government", culture: "leonese"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.at, func(t *testing.T) {
+			state := timeline.At(date(t, tt.at))
+
+			if state.Name != "Teresa" {
+				t.Errorf("Name = %q, want %q", state.Name, "Teresa")
+			}
+			if state.Alive() != tt.alive {
+				t.Errorf("Alive() = %v, want %v", state.Alive(), tt.alive)
+			}
+			if len(state.Traits) != len(tt.traits) {
+				t.Fatalf("Traits = %v, want %v", state.Traits, tt.traits)
+			}
+			for i := range tt.traits {
+				if state.Traits[i] != tt.traits[i] {
+					t.Errorf("Traits = %v, want %v", state.Traits, tt.traits)
+				}
+			}
+			if state.Capital != tt.capital {
+				t.Errorf("Capital = %q, want %q", state.Capital, tt.capital)
+			}
+			if state.Government != tt.government {
+				t.Errorf("Government = %q, want %q", state.Government, tt.government)
+			}
+			if state.Fields["culture"] != tt.culture {
+				t.Errorf("culture = %q, want %q", state.Fields["culture"], tt.culture)
+			}
+		})
+	}
+}
+
+func TestTimeline_Latest(t *testing.T) {
+	timeline, _, err := history.Find(testutil.ParseString(t, character), "70027")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	state := timeline.Latest()
+	if !state.Dead || state.Death == nil || *state.Death != date(t, "997.1.1") {
+		t.Errorf("Latest() death = %v, want 997.1.1", state.Death)
+	}
+}
+
+func TestNewTimeline_InvalidDate(t *testing.T) {
+	tree := testutil.ParseString(t, "1 = {\n\t941.13.1 = { birth = yes }\n}\n")
+
+	timeline, diagnostics, err := history.Find(tree, "1")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(diagnostics) != 1 {
+		t.Errorf("Find() returned %d diagnostics, want 1", len(diagnostics))
+	}
+	if len(timeline.Events) != 0 {
+		t.Errorf("Events = %d, want 0", len(timeline.Events))
+	}
+}
+
+func TestFind_Missing(t *testing.T) {
+	if _, _, err := history.Find(testutil.ParseString(t, character), "1"); err == nil {
+		t.Error("Find() expected an error for a missing entry")
+	}
+}
+
+func TestTimeline_BaseBirth(t *testing.T) {
+	const entry = `70028 = {
+	birth = yes
+	death = yes
+	1000.1.1 = {
+		trait = brave
+	}
+}
+`
+	timeline, _, err := history.Find(testutil.ParseString(t, entry), "70028")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	state := timeline.Latest()
+	if state.Born || state.Birth != nil {
+		t.Errorf("Latest() birth = %v, want none outside dated blocks", state.Birth)
+	}
+	if state.Dead || state.Death != nil {
+		t.Errorf("Latest() death = %v, want none outside dated blocks", state.Death)
+	}
+}
@@ -4,13 +4,19 @@ package lexer
 import (
 	"bytes"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/unLomTrois/gock3/pkg/files"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
 	"github.com/unLomTrois/gock3/pkg/tokens"
 )
 
+const (
+	errSmartQuote = "Typographic quote '%c' instead of '\"'"
+	fixSmartQuote = "replace with a straight quote"
+)
+
 type Lexer struct {
 	file           files.ParadoxFile
 	text           []byte
@@ -52,7 +58,12 @@ func Scan(file files.ParadoxFile, text []byte) (*tokens.TokenStream, []*report.D
 	tokenStream := tokens.NewTokenStream()
 
 	for lex.hasMoreTokens() {
-		if token := lex.getNextToken(); token != nil {
+		token := lex.getNextToken()
+		switch {
+		case token == nil:
+		case token.Type == tokens.COMMENT:
+			tokenStream.PushComment(token)
+		default:
 			tokenStream.Push(token)
 		}
 	}
@@ -105,8 +116,6 @@ func (lex *Lexer) processMatch(tokenType tokens.TokenType, match []byte, startLi
 	case tokens.WHITESPACE:
 		lex.column++
 		return nil
-	case tokens.COMMENT:
-		return nil
 	default:
 		lex.column += len(match)
 		loc := tokens.LocFromParadoxFile(lex.file)
@@ -116,18 +125,38 @@ func (lex *Lexer) processMatch(tokenType tokens.TokenType, match []byte, startLi
 	}
 }
 
-// reportUnexpectedToken logs an error for an unexpected token and advances the cursor.
+// reportUnexpectedToken logs an error for an unexpected character and advances the cursor past it.
 func (lex *Lexer) reportUnexpectedToken() {
-	remaining := lex.remainder()
-	unexpectedChar := remaining[0]
+	char, size := utf8.DecodeRune(lex.remainder())
 
 	loc := tokens.LocFromParadoxFile(lex.file)
 	loc.Line = uint32(lex.line)
 	loc.Column = uint16(lex.column)
-	err := report.FromLoc(*loc, severity.Critical, fmt.Sprintf("unexpected token '%c'", unexpectedChar))
+
+	var err *report.DiagnosticItem
+	if isSmartQuote(char) {
+		err = report.FromLoc(*loc, codes.SmartQuote, fmt.Sprintf(errSmartQuote, char))
+		err.Fixes = []*report.Fix{{
+			Msg:   fixSmartQuote,
+			Edits: []*report.Edit{{Loc: *loc, Length: size, Text: `"`}},
+		}}
+	} else {
+		err = report.FromLoc(*loc, codes.UnexpectedCharacter, fmt.Sprintf("unexpected token '%c'", char))
+	}
+	err.Pointer.Length = size
 	lex.AddError(err)
 
-	// Advance to prevent an infinite loop.
-	lex.cursor++
-	lex.column++
+	// Advance to prevent an infinite loop. Columns count bytes, as for other tokens.
+	lex.cursor += size
+	lex.column += size
+}
+
+// isSmartQuote reports whether a character is a typographic double quote, as inserted by
+// word processors. Single quotes are left alone, they are usually meant as apostrophes.
+func isSmartQuote(char rune) bool {
+	switch char {
+	case '\u201C', '\u201D', '\u201E':
+		return true
+	}
+	return false
 }
@@ -3,9 +3,11 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/unLomTrois/gock3/pkg/ast"
 	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
 	"github.com/unLomTrois/gock3/pkg/report/severity"
 	"github.com/unLomTrois/gock3/pkg/tokens"
 )
@@ -13,13 +15,15 @@ import (
 // Block parses a block and returns the corresponding AST node.
 func (p *Parser) Block() ast.Block {
 	// Expect the start of a block.
-	p.Expect(tokens.START)
+	open := p.Expect(tokens.START)
 	loc := *p.loc
+	p.depth++
+	defer func() { p.depth-- }()
 
 	// Handle an empty block.
-	if p.currentToken.Type == tokens.END {
-		p.Expect(tokens.END)
-		return &ast.FieldBlock{Values: []*ast.Field{}, Loc: loc}
+	if p.currentToken != nil && p.currentToken.Type == tokens.END {
+		end := p.Expect(tokens.END)
+		return &ast.FieldBlock{Values: []*ast.Field{}, Loc: loc, End: end.Loc}
 	}
 
 	var block ast.Block
@@ -33,11 +37,11 @@ func (p *Parser) Block() ast.Block {
 			if p.isNextField() {
 				block = p.FieldBlock(loc)
 			} else {
-				block = p.TokenBlock()
+				block = p.TokenBlock(loc)
 			}
 		default:
 			errorMsg := fmt.Sprintf(errBlockUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-			err := report.FromToken(p.currentToken, severity.Error, errorMsg)
+			err := report.FromToken(p.currentToken, codes.UnexpectedBlockToken, errorMsg)
 			p.AddError(err)
 			p.synchronize(BlockRecovery)
 			continue
@@ -46,11 +50,83 @@ func (p *Parser) Block() ast.Block {
 		break
 	}
 
+	// A block holding only line breaks is empty.
+	if block == nil && p.currentToken != nil && p.currentToken.Type == tokens.END {
+		block = &ast.FieldBlock{Values: []*ast.Field{}, Loc: loc}
+	}
+
+	if p.currentToken == nil {
+		p.reportMissingClosingBrace(open)
+		return block
+	}
+	if p.currentToken.Type != tokens.END {
+		p.reportUnclosedBlock(open)
+		return block
+	}
+
 	// Expect the closing token for the block.
-	p.Expect(tokens.END)
+	if end := p.Expect(tokens.END); end != nil {
+		switch b := block.(type) {
+		case *ast.FieldBlock:
+			b.End = end.Loc
+		case *ast.TokenBlock:
+			b.End = end.Loc
+		}
+	}
 	return block
 }
 
+// reportMissingClosingBrace reports a block left open at the end of the file, with a fix
+// closing it on its own line.
+func (p *Parser) reportMissingClosingBrace(open *tokens.Token) {
+	err := report.FromLoc(*p.loc, codes.MissingClosingBrace, errMissingClosingBrace)
+	err.Related = blockOpenedAt(open)
+	err.Notes = []string{noteMissingClosingBrace}
+
+	// After other syntax errors the brace may have been skipped by recovery, rather than
+	// be missing, so only a file without them gets a fix
+	for _, other := range p.Errors() {
+		if other.Code != codes.MissingClosingBrace && other.Severity >= severity.Error {
+			p.AddError(err)
+			return
+		}
+	}
+
+	text := strings.Repeat("\t", p.depth-1) + "}"
+	if p.loc.Column == 1 {
+		text += "\n"
+	} else {
+		text = "\n" + text
+	}
+	err.Fixes = []*report.Fix{{
+		Msg:   fixMissingClosingBrace,
+		Edits: []*report.Edit{{Loc: *p.loc, Text: text}},
+	}}
+	p.AddError(err)
+}
+
+// reportUnclosedBlock reports a token found where the closing brace of a block is
+// expected, and skips to the closing brace.
+func (p *Parser) reportUnclosedBlock(open *tokens.Token) {
+	errMsg := fmt.Sprintf(errUnexpectedToken, p.currentToken.Value, p.currentToken.Type, formatTokenTypes([]tokens.TokenType{tokens.END}))
+	err := report.FromToken(p.currentToken, codes.UnexpectedToken, errMsg)
+	err.Related = blockOpenedAt(open)
+	p.AddError(err)
+
+	p.synchronize(RecoveryPoint{TokenTypes: []tokens.TokenType{tokens.END}, Context: "block"})
+}
+
+// blockOpenedAt points at the opening brace of a block.
+func blockOpenedAt(open *tokens.Token) []*report.RelatedLocation {
+	if open == nil {
+		return nil
+	}
+	return []*report.RelatedLocation{{
+		Pointer: &report.DiagnosticPointer{Loc: open.Loc, Length: len(open.Value)},
+		Msg:     relatedBlockOpened,
+	}}
+}
+
 func (p *Parser) skipTokens(types ...tokens.TokenType) {
 	for p.currentToken != nil {
 		matched := false
@@ -74,9 +150,9 @@ func (p *Parser) FieldBlock(loc tokens.Loc) *ast.FieldBlock {
 }
 
 // TokenBlock parses a block of tokens and returns the corresponding AST node.
-func (p *Parser) TokenBlock() *ast.TokenBlock {
+func (p *Parser) TokenBlock(loc tokens.Loc) *ast.TokenBlock {
 	tokensList := p.TokenList(tokens.END)
-	return &ast.TokenBlock{Values: tokensList}
+	return &ast.TokenBlock{Values: tokensList, Loc: loc}
 }
 
 // TokenList parses a list of tokens until a specified stop token is encountered.
@@ -96,7 +172,7 @@ func (p *Parser) TokenList(stopLookahead ...tokens.TokenType) []*tokens.Token {
 			}
 		default:
 			errMsg := fmt.Sprintf(errTokenListUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-			err := report.FromToken(p.currentToken, severity.Error, errMsg)
+			err := report.FromToken(p.currentToken, codes.UnexpectedBlockToken, errMsg)
 			p.AddError(err)
 			recoveryPoint := RecoveryPoint{
 				TokenTypes: []tokens.TokenType{tokens.END, tokens.WORD, tokens.DATE},
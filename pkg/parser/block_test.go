@@ -0,0 +1,69 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+)
+
+func parse(t *testing.T, content string) []*report.DiagnosticItem {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, diagnostics, err := parser.ParseParadoxFile(files.NewParadoxTxtFile(path, files.Mod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return diagnostics
+}
+
+func find(diagnostics []*report.DiagnosticItem, code codes.Code) *report.DiagnosticItem {
+	for _, diag := range diagnostics {
+		if diag.Code == code {
+			return diag
+		}
+	}
+	return nil
+}
+
+func TestBlock_MissingClosingBrace(t *testing.T) {
+	diag := find(parse(t, "brave = {\n\tcost = 1\n\tmodifier = {\n\t\tx = 1\n\t}\n"), codes.MissingClosingBrace)
+	if diag == nil {
+		t.Fatal("missing brace not reported")
+	}
+	if len(diag.Related) != 1 || diag.Related[0].Pointer.Loc.Line != 1 || diag.Related[0].Pointer.Loc.Column != 9 {
+		t.Errorf("related = %+v, want the opening brace at 1:9", diag.Related)
+	}
+	if len(diag.Fixes) != 1 {
+		t.Errorf("got %d fixes, want 1", len(diag.Fixes))
+	}
+}
+
+func TestBlock_MissingClosingBraceAfterErrors(t *testing.T) {
+	// Recovery skips the closing brace, which is not missing
+	diag := find(parse(t, "a = { b = = = c }\n"), codes.MissingClosingBrace)
+	if diag != nil && len(diag.Fixes) != 0 {
+		t.Errorf("a fix is suggested for a brace skipped by recovery")
+	}
+}
+
+func TestSynchronize_Related(t *testing.T) {
+	diag := find(parse(t, "brave = {\n\tcost = = = 10\n}\n"), codes.SkippedSyntax)
+	if diag == nil {
+		t.Fatal("skipped syntax not reported")
+	}
+	if len(diag.Related) != 1 || diag.Related[0].Msg == "" {
+		t.Errorf("related = %+v, want where parsing resumes", diag.Related)
+	}
+	if len(diag.Notes) == 0 {
+		t.Errorf("expected a note")
+	}
+}
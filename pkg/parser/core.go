@@ -6,7 +6,7 @@ import (
 	"strconv"
 
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
 	"github.com/unLomTrois/gock3/pkg/tokens"
 )
 
@@ -17,7 +17,7 @@ func (p *Parser) Expect(expectedTypes ...tokens.TokenType) *tokens.Token {
 	token := p.currentToken
 	if token == nil {
 		errMsg := fmt.Sprintf(errUnexpectedEOF, formatTokenTypes(expectedTypes))
-		err := report.FromLoc(*p.loc, severity.Error, errMsg)
+		err := report.FromLoc(*p.loc, codes.UnexpectedEOF, errMsg)
 		p.AddError(err)
 		return nil
 	}
@@ -30,7 +30,7 @@ func (p *Parser) Expect(expectedTypes ...tokens.TokenType) *tokens.Token {
 	}
 
 	errMsg := fmt.Sprintf(errUnexpectedToken, token.Value, token.Type, formatTokenTypes(expectedTypes))
-	err := report.FromToken(token, severity.Error, errMsg)
+	err := report.FromToken(token, codes.UnexpectedToken, errMsg)
 	p.AddError(err)
 
 	recoveryPoint := RecoveryPoint{
@@ -52,7 +52,7 @@ func (p *Parser) unquoteExpect(expectedType tokens.TokenType) *tokens.Token {
 	unquotedValue, err := strconv.Unquote(token.Value)
 	if err != nil {
 		errMsg := fmt.Sprintf(errFailedUnquoteString, token.Value)
-		diag := report.FromToken(token, severity.Error, errMsg)
+		diag := report.FromToken(token, codes.InvalidQuotedString, errMsg)
 		p.AddError(diag)
 		// Keep the original value if unquoting fails
 		return token
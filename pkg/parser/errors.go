@@ -6,16 +6,39 @@ const (
 	errUnexpectedToken = "Unexpected token %q of type %q, expected one of: %s"
 
 	// Additional error messages
-	errFieldListUnexpectedToken = "[FieldList] Unexpected token %q of type %q"
 	errUnexpectedFieldToken     = "Unexpected token %q of type %q when expecting a field"
+	errKeyExpectedEOF           = "Expected a key, but reached end of input"
+	errKeyUnexpectedToken       = "Expected a key (WORD, DATE, or NUMBER), but found %q of type %q"
 	errOperatorExpectedEOF      = "Expected an operator '=', '==', or comparison, but reached end of input"
 	errOperatorUnexpectedToken  = "Expected operator '=', '==', or comparison, but found %q of type %q"
 	errValueExpectedEOF         = "Expected a value, but reached end of input"
-	errValueUnexpectedToken     = "[Value] Unexpected token %q of type %q"
-	errBlockUnexpectedToken     = "[Block] Unexpected token %q of type %q in block"
-	errTokenListUnexpectedToken = "[TokenList] Unexpected token %q of type %q in token list"
+	errValueUnexpectedToken     = "Unexpected token %q of type %q when expecting a value"
+	errBlockUnexpectedToken     = "Unexpected token %q of type %q in block"
+	errTokenListUnexpectedToken = "Unexpected token %q of type %q in list of values"
 	errLiteralExpectedEOF       = "Unexpected end of input when expecting a literal value"
 	errLiteralUnexpectedToken   = "Unexpected token %q of type %q when expecting a literal value (word, number, boolean, or quoted string)"
 	errRecoveredNonLiteralToken = "Recovered to non-literal token %q of type %q after error"
 	errFailedUnquoteString      = "Failed to unquote string %q"
+	errNumberPrecisionLoss      = "Number %q has more than three decimals, the game only keeps thousandths"
+	helpNumberPrecisionLoss     = "the game reads it as %s"
+	errSkippedSyntax            = "Skipped invalid syntax in %q: %q"
+	errRecoveryFailed           = "Failed to recover while parsing %s - too many invalid tokens"
+	errMissingClosingBrace      = "Missing closing brace, the block is still open at the end of the file"
+	errDoubleEquals             = "Operator '==' is a comparison, but the value is assigned"
+)
+
+// Labels of related locations and notes
+const (
+	relatedBlockOpened      = "block opened here"
+	relatedResumed          = "parsing resumes here"
+	relatedRecoveryStopped  = "gave up here after skipping %d tokens"
+	noteMissingClosingBrace = "every field after the opening brace belongs to the block"
+	noteSkippedSyntax       = "the skipped tokens are ignored, fix the error reported before this warning"
+	noteRecoveryFailed      = "the rest of the %s is not checked"
+)
+
+// Fix messages
+const (
+	fixMissingClosingBrace = "close the block at the end of the file"
+	fixDoubleEquals        = "replace with '='"
 )
@@ -2,12 +2,14 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/unLomTrois/gock3/pkg/ast"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
 	"github.com/unLomTrois/gock3/pkg/tokens"
+	"github.com/unLomTrois/gock3/pkg/values"
 )
 
 // fileBlock parses the entire file and constructs the AST's FileBlock.
@@ -41,19 +43,18 @@ func (p *Parser) FieldList(stopLookahead ...tokens.TokenType) []*ast.Field {
 			}
 		default:
 			// Handle unexpected token
-			errMsg := fmt.Sprintf(errFieldListUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-			err := report.FromToken(p.currentToken, severity.Error, errMsg)
+			errMsg := fmt.Sprintf(errUnexpectedFieldToken, p.currentToken.Value, p.currentToken.Type)
+			unexpected := p.currentToken
+			err := report.FromToken(unexpected, codes.ExpectedField, errMsg)
 			p.AddError(err)
 
-			if fullPath, errPath := p.currentToken.Loc.Fullpath(); errPath == nil {
-				_ = fullPath // Optionally, log or use the full path.
-			} else {
-				p.AddError(report.FromLoc(*p.loc, severity.Error, errPath.Error()))
-			}
-
 			if _, recovered := p.synchronize(FieldListRecovery); !recovered {
 				return fields
 			}
+			// A stray closing brace is itself a recovery point, skip it to make progress.
+			if p.currentToken == unexpected {
+				p.nextToken()
+			}
 		}
 	}
 	return fields
@@ -66,7 +67,7 @@ func (p *Parser) Field() *ast.Field {
 		return p.ExpressionNode()
 	default:
 		errMsg := fmt.Sprintf(errUnexpectedFieldToken, p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		err := report.FromToken(p.currentToken, codes.ExpectedField, errMsg)
 		p.AddError(err)
 		p.synchronize(FieldRecovery)
 		return nil
@@ -89,6 +90,7 @@ func (p *Parser) ExpressionNode() *ast.Field {
 	if value == nil {
 		return nil
 	}
+	p.checkDoubleEquals(operator, value)
 
 	return &ast.Field{
 		Key:      key,
@@ -97,11 +99,34 @@ func (p *Parser) ExpressionNode() *ast.Field {
 	}
 }
 
+// checkDoubleEquals warns about the comparison operator '==' used to assign a block or a
+// boolean, where '=' is meant: neither can be compared.
+func (p *Parser) checkDoubleEquals(operator *tokens.Token, value ast.BlockOrValue) {
+	if operator.Value != "==" {
+		return
+	}
+	switch value := value.(type) {
+	case ast.Block:
+	case *tokens.Token:
+		if value.Type != tokens.BOOL {
+			return
+		}
+	default:
+		return
+	}
+
+	diag := report.FromToken(operator, codes.DoubleEquals, errDoubleEquals)
+	diag.Fixes = []*report.Fix{{
+		Msg:   fixDoubleEquals,
+		Edits: []*report.Edit{report.ReplaceToken(operator, "=")},
+	}}
+	p.AddError(diag)
+}
+
 // Key parses the key of a field and returns the corresponding token.
 func (p *Parser) Key() *tokens.Token {
 	if p.currentToken == nil {
-		errMsg := "Expected a key, but reached end of input"
-		err := report.FromLoc(*p.loc, severity.Error, errMsg)
+		err := report.FromLoc(*p.loc, codes.UnexpectedEOF, errKeyExpectedEOF)
 		p.AddError(err)
 		return nil
 	}
@@ -110,8 +135,8 @@ func (p *Parser) Key() *tokens.Token {
 	case tokens.WORD, tokens.DATE, tokens.NUMBER:
 		return p.Expect(tokens.WORD, tokens.DATE, tokens.NUMBER)
 	default:
-		errMsg := fmt.Sprintf("Expected a key (WORD, DATE, or NUMBER), but found %q of type %q", p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		errMsg := fmt.Sprintf(errKeyUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
+		err := report.FromToken(p.currentToken, codes.ExpectedField, errMsg)
 		p.AddError(err)
 		p.synchronize(KeyRecovery)
 		return nil
@@ -121,8 +146,7 @@ func (p *Parser) Key() *tokens.Token {
 // Operator parses the operator of a field and returns the corresponding token.
 func (p *Parser) Operator() *tokens.Token {
 	if p.currentToken == nil {
-		errMsg := errOperatorExpectedEOF
-		err := report.FromLoc(*p.loc, severity.Error, errMsg)
+		err := report.FromLoc(*p.loc, codes.UnexpectedEOF, errOperatorExpectedEOF)
 		p.AddError(err)
 		return nil
 	}
@@ -132,7 +156,7 @@ func (p *Parser) Operator() *tokens.Token {
 		return p.Expect(p.currentToken.Type)
 	default:
 		errMsg := fmt.Sprintf(errOperatorUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		err := report.FromToken(p.currentToken, codes.ExpectedOperator, errMsg)
 		p.AddError(err)
 		p.synchronize(ValueRecovery)
 		return nil
@@ -142,8 +166,7 @@ func (p *Parser) Operator() *tokens.Token {
 // Value parses the value of a field and returns the corresponding AST node.
 func (p *Parser) Value() ast.BlockOrValue {
 	if p.currentToken == nil {
-		errMsg := errValueExpectedEOF
-		err := report.FromLoc(*p.loc, severity.Error, errMsg)
+		err := report.FromLoc(*p.loc, codes.UnexpectedEOF, errValueExpectedEOF)
 		p.AddError(err)
 		return nil
 	}
@@ -152,19 +175,38 @@ func (p *Parser) Value() ast.BlockOrValue {
 	case tokens.NEXTLINE:
 		p.Expect(tokens.NEXTLINE)
 		return p.EmptyValue()
-	case tokens.WORD, tokens.NUMBER, tokens.QUOTED_STRING, tokens.BOOL, tokens.DATE:
+	case tokens.NUMBER:
+		return p.Number()
+	case tokens.WORD, tokens.QUOTED_STRING, tokens.BOOL, tokens.DATE:
 		return p.Literal()
 	case tokens.START:
 		return p.Block()
 	default:
 		errMsg := fmt.Sprintf(errValueUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		err := report.FromToken(p.currentToken, codes.ExpectedValue, errMsg)
 		p.AddError(err)
 		p.synchronize(ValueRecovery)
 		return nil
 	}
 }
 
+// Number parses a numeric value and warns when the game would drop some of its precision.
+func (p *Parser) Number() *tokens.Token {
+	token := p.Expect(tokens.NUMBER)
+	if token == nil {
+		return nil
+	}
+
+	if fixed, err := token.FixedValue(); errors.Is(err, values.ErrPrecisionLoss) {
+		errMsg := fmt.Sprintf(errNumberPrecisionLoss, token.Value)
+		diag := report.FromToken(token, codes.NumberPrecisionLoss, errMsg)
+		diag.Help = fmt.Sprintf(helpNumberPrecisionLoss, fixed)
+		p.AddError(diag)
+	}
+
+	return token
+}
+
 // EmptyValue returns an empty value AST node.
 func (p *Parser) EmptyValue() ast.BlockOrValue {
 	return &ast.EmptyValue{
@@ -175,7 +217,7 @@ func (p *Parser) EmptyValue() ast.BlockOrValue {
 // Literal parses a literal token and returns it.
 func (p *Parser) Literal() *tokens.Token {
 	if p.currentToken == nil {
-		err := report.FromLoc(*p.loc, severity.Error, errLiteralExpectedEOF)
+		err := report.FromLoc(*p.loc, codes.UnexpectedEOF, errLiteralExpectedEOF)
 		p.AddError(err)
 		return nil
 	}
@@ -187,7 +229,7 @@ func (p *Parser) Literal() *tokens.Token {
 		return p.unquoteExpect(tokens.QUOTED_STRING)
 	default:
 		errMsg := fmt.Sprintf(errLiteralUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		err := report.FromToken(p.currentToken, codes.ExpectedValue, errMsg)
 		p.AddError(err)
 	}
 
@@ -198,7 +240,7 @@ func (p *Parser) Literal() *tokens.Token {
 		}
 		// If recovered to a non-literal token, give up
 		errMsg := fmt.Sprintf(errRecoveredNonLiteralToken, token.Value, token.Type)
-		err := report.FromToken(token, severity.Error, errMsg)
+		err := report.FromToken(token, codes.ExpectedValue, errMsg)
 		p.AddError(err)
 	}
 
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/report"
+)
+
+// FileResult is the outcome of parsing one of the files given to ParseFiles.
+type FileResult struct {
+	File        files.ParadoxFile
+	AST         *ast.AST
+	Diagnostics []*report.DiagnosticItem
+	// Err is set if the file couldn't be read, AST and Diagnostics are nil then.
+	Err error
+	// Duration is the time spent reading and parsing the file.
+	Duration time.Duration
+}
+
+// ParseFiles parses the files concurrently with at most workers goroutines, or one
+// per CPU if workers is 0 or less. The results are in the order of the files.
+func ParseFiles(paradoxFiles []files.ParadoxFile, workers int) []*FileResult {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(paradoxFiles) {
+		workers = len(paradoxFiles)
+	}
+
+	results := make([]*FileResult, len(paradoxFiles))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = parseFile(paradoxFiles[i])
+			}
+		}()
+	}
+
+	for i := range paradoxFiles {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func parseFile(file files.ParadoxFile) *FileResult {
+	start := time.Now()
+	tree, diagnostics, err := ParseParadoxFile(file)
+	return &FileResult{
+		File:        file,
+		AST:         tree,
+		Diagnostics: diagnostics,
+		Err:         err,
+		Duration:    time.Since(start),
+	}
+}
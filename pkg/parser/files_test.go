@@ -0,0 +1,100 @@
+package parser_test
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+)
+
+func TestParseFiles(t *testing.T) {
+	dir := t.TempDir()
+	var paradoxFiles []files.ParadoxFile
+	for i := range 20 {
+		path := filepath.Join(dir, fmt.Sprintf("%02d.txt", i))
+		content := fmt.Sprintf("trait_%d = { cost = %d }\n", i, i)
+		if i%5 == 0 {
+			// Missing closing brace
+			content = fmt.Sprintf("trait_%d = {\n", i)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paradoxFiles = append(paradoxFiles, files.NewParadoxTxtFile(path, files.Mod))
+	}
+
+	for _, workers := range []int{0, 1, 3, 100} {
+		results := parser.ParseFiles(paradoxFiles, workers)
+		if len(results) != len(paradoxFiles) {
+			t.Fatalf("%d workers: got %d results, want %d", workers, len(results), len(paradoxFiles))
+		}
+		for i, result := range results {
+			if result.Err != nil {
+				t.Fatalf("%d workers: %v", workers, result.Err)
+			}
+			if result.File != paradoxFiles[i] || result.AST.Fullpath != paradoxFiles[i].FullPath() {
+				t.Errorf("%d workers: result %d is for %s", workers, i, result.AST.Fullpath)
+			}
+			if got, want := len(result.Diagnostics) > 0, i%5 == 0; got != want {
+				t.Errorf("%d workers: file %d has diagnostics %v, want %v", workers, i, got, want)
+			}
+		}
+	}
+
+	if results := parser.ParseFiles(nil, 4); len(results) != 0 {
+		t.Errorf("got %d results without files", len(results))
+	}
+}
+
+//go:embed testdata
+var testdata embed.FS
+
+func TestParseParadoxFile_FS(t *testing.T) {
+	content, err := testdata.ReadFile("testdata/unclosed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, fsys := range map[string]fs.FS{
+		"embed":   testdata,
+		"map":     fstest.MapFS{"testdata/unclosed.txt": {Data: content}},
+		"overlay": overlay(t, "testdata/unclosed.txt", content),
+	} {
+		t.Run(name, func(t *testing.T) {
+			file, err := files.NewParadoxFSFile(fsys, "testdata/unclosed.txt", "mod.zip!/testdata/unclosed.txt", files.Mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tree, diagnostics, err := parser.ParseParadoxFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tree.Block.Values) != 1 || tree.Block.Values[0].Key.Value != "brave" {
+				t.Errorf("unexpected AST, the BOM should be skipped")
+			}
+			diag := find(diagnostics, codes.MissingClosingBrace)
+			if diag == nil {
+				t.Fatalf("no missing brace diagnostic in %v", diagnostics)
+			}
+			if path, _ := diag.Pointer.Loc.Pathname(); path != "mod.zip!/testdata/unclosed.txt" {
+				t.Errorf("diagnostic in %s", path)
+			}
+		})
+	}
+}
+
+func overlay(t *testing.T, name string, content []byte) fs.FS {
+	t.Helper()
+	o := files.NewOverlay(nil)
+	if err := o.Set(name, content); err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
@@ -2,14 +2,14 @@ package parser
 
 import (
 	"fmt"
-	"strconv"
+	"strings"
 
 	"github.com/unLomTrois/gock3/internal/utils"
 	"github.com/unLomTrois/gock3/pkg/ast"
-	"github.com/unLomTrois/gock3/pkg/cache"
 	"github.com/unLomTrois/gock3/pkg/files"
 	"github.com/unLomTrois/gock3/pkg/lexer"
 	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
 	"github.com/unLomTrois/gock3/pkg/tokens"
 )
 
@@ -19,6 +19,8 @@ type Parser struct {
 	currentToken *tokens.Token
 	lookahead    *tokens.Token
 	loc          *tokens.Loc
+	// depth is the number of blocks being parsed.
+	depth int
 	*report.ErrorManager
 }
 
@@ -45,12 +47,14 @@ func ParseTokenStream(tokenStream *tokens.TokenStream) (*ast.FileBlock, []*repor
 }
 
 // ParseParadoxFile is the high-level entry point that reads, tokenizes, and parses a
-// Paradox file into an AST.
-func ParseParadoxFile(file files.ParadoxFile) (*ast.AST, error) {
-	content, err := utils.ReadFileWithUTF8BOM(file.FullPath())
+// Paradox file into an AST. Lexer and parser diagnostics are returned for the caller
+// to report, see report.Reporter.
+func ParseParadoxFile(file files.ParadoxFile) (*ast.AST, []*report.DiagnosticItem, error) {
+	content, err := file.ReadFile()
 	if err != nil {
-		return nil, fmt.Errorf("reading file: %w", err)
+		return nil, nil, fmt.Errorf("reading file: %w", err)
 	}
+	content = utils.TrimUTF8BOM(content)
 
 	diagnostics := []*report.DiagnosticItem{}
 	tokenStream, lexerErrors := lexer.Scan(file, content)
@@ -63,54 +67,38 @@ func ParseParadoxFile(file files.ParadoxFile) (*ast.AST, error) {
 		Filename: file.FileName(),
 		Fullpath: file.FullPath(),
 		Block:    fileBlock,
+		Comments: tokenStream.Comments,
 	}
 
-	reportDiagnostics(diagnostics)
+	return astTree, diagnostics, nil
+}
 
-	return astTree, nil
+// Codes returns the codes of the diagnostics of ParseParadoxFile, those of the lexer
+// and of the parser.
+func Codes() []codes.Code {
+	var result []codes.Code
+	for _, entry := range codes.All() {
+		if strings.HasPrefix(string(entry.Code), "L") || strings.HasPrefix(string(entry.Code), "P") {
+			result = append(result, entry.Code)
+		}
+	}
+	return result
 }
 
 // nextToken advances the token stream.
 func (p *Parser) nextToken() {
+	previous := p.currentToken
 	p.currentToken = p.lookahead
 	p.lookahead = p.tokenstream.Next()
 	if p.currentToken != nil {
 		p.loc = &p.currentToken.Loc
-	} else {
-		p.loc = nil
-	}
-}
-
-// reportDiagnostics outputs all diagnostic items.
-func reportDiagnostics(diagnostics []*report.DiagnosticItem) {
-	fileCache := cache.NewFileCache()
-	for _, diag := range diagnostics {
-		printDiagnostic(diag, fileCache)
-	}
-}
-
-func printDiagnostic(diag *report.DiagnosticItem, fileCache *cache.FileCache) {
-	color := diag.Severity.Color()
-	filename, _ := diag.Pointer.Loc.Filename()
-	line := diag.Pointer.Loc.Line
-	column := diag.Pointer.Loc.Column
-
-	// Special-case: if the error is at the very beginning, output minimal information.
-	if line == 1 && column == 1 {
-		color.Printf("[%s:%d:%d]: %s\n", filename, line, column, diag.Msg)
-		return
-	}
-
-	errLine := getErrorLine(fileCache, diag, column)
-	color.Printf("[%s:%d:%d]: %s, got %s\n", filename, line, column, diag.Msg, strconv.Quote(errLine))
-}
-
-func getErrorLine(fileCache *cache.FileCache, diag *report.DiagnosticItem, column uint16) string {
-	lineText := fileCache.GetLine(&diag.Pointer.Loc)
-	normalizedLine := lineText // Optionally, normalize tabs/spaces if needed.
-	errorEndIndex := int(column) + int(diag.Pointer.Length) - 1
-	if errorEndIndex > len(normalizedLine) {
-		errorEndIndex = len(normalizedLine)
+	} else if previous != nil {
+		// Past the last token, end-of-input errors point right after it.
+		// Line breaks are located at the start of the line they open.
+		loc := previous.Loc
+		if previous.Type != tokens.NEXTLINE {
+			loc.Column += uint16(len(previous.Value))
+		}
+		p.loc = &loc
 	}
-	return normalizedLine[:errorEndIndex]
 }
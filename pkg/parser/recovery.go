@@ -5,7 +5,7 @@ import (
 	"strings"
 
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
 	"github.com/unLomTrois/gock3/pkg/tokens"
 )
 
@@ -86,7 +86,7 @@ func (p *Parser) synchronize(point RecoveryPoint) (*tokens.Token, bool) {
 			if p.currentToken.Type == expectedType {
 				// Found recovery point - report skipped section.
 				if len(skipped) > 0 {
-					p.reportSkippedSection(startLoc, skipped, point.Context)
+					p.reportSkippedSection(startLoc, skipped, point.Context, p.currentToken)
 				}
 				return p.currentToken, true
 			}
@@ -98,25 +98,45 @@ func (p *Parser) synchronize(point RecoveryPoint) (*tokens.Token, bool) {
 		skippedTokens++
 	}
 
-	p.reportRecoveryFailure(startLoc, point.Context)
+	p.reportRecoveryFailure(startLoc, point.Context, skippedTokens)
 	return nil, false
 }
 
-// reportSkippedSection logs the tokens skipped during recovery.
-func (p *Parser) reportSkippedSection(startLoc tokens.Loc, skipped []*tokens.Token, context string) {
+// reportSkippedSection logs the tokens skipped during recovery, pointing at the token
+// where parsing resumes.
+func (p *Parser) reportSkippedSection(startLoc tokens.Loc, skipped []*tokens.Token, context string, resume *tokens.Token) {
 	var skippedValues []string
 	for _, t := range skipped {
 		skippedValues = append(skippedValues, fmt.Sprintf("%s (%s)", t.Value, t.Type))
 	}
 
-	errMsg := fmt.Sprintf("Skipped invalid syntax in %q: %q", context, strings.Join(skippedValues, ", "))
-	err := report.FromLoc(startLoc, severity.Warning, errMsg)
+	errMsg := fmt.Sprintf(errSkippedSyntax, context, strings.Join(skippedValues, ", "))
+	err := report.FromLoc(startLoc, codes.SkippedSyntax, errMsg)
+	// Underline the skipped tokens on the line where recovery started
+	for _, t := range skipped {
+		if t.Loc.Line == startLoc.Line && t.Loc.Column >= startLoc.Column {
+			err.Pointer.Length = int(t.Loc.Column-startLoc.Column) + len(t.Value)
+		}
+	}
+	err.Related = []*report.RelatedLocation{{
+		Pointer: &report.DiagnosticPointer{Loc: resume.Loc, Length: len(resume.Value)},
+		Msg:     relatedResumed,
+	}}
+	err.Notes = []string{noteSkippedSyntax}
 	p.AddError(err)
 }
 
-// reportRecoveryFailure logs a failure to recover.
-func (p *Parser) reportRecoveryFailure(startLoc tokens.Loc, context string) {
-	errMsg := fmt.Sprintf("Failed to recover while parsing %s - too many invalid tokens", context)
-	err := report.FromLoc(startLoc, severity.Error, errMsg)
+// reportRecoveryFailure logs a failure to recover, pointing at the token where
+// recovery stopped.
+func (p *Parser) reportRecoveryFailure(startLoc tokens.Loc, context string, skipped int) {
+	errMsg := fmt.Sprintf(errRecoveryFailed, context)
+	err := report.FromLoc(startLoc, codes.RecoveryFailed, errMsg)
+	if p.currentToken != nil {
+		err.Related = []*report.RelatedLocation{{
+			Pointer: &report.DiagnosticPointer{Loc: p.currentToken.Loc, Length: len(p.currentToken.Value)},
+			Msg:     fmt.Sprintf(relatedRecoveryStopped, skipped),
+		}}
+	}
+	err.Notes = []string{fmt.Sprintf(noteRecoveryFailed, context)}
 	p.AddError(err)
 }
@@ -0,0 +1,307 @@
+// Package codes is the catalog of diagnostic codes. Every diagnostic reported by the
+// lexer, parser and validators carries one of these stable codes, so it can be filtered,
+// suppressed and documented independently of its message.
+//
+// Codes are never reused or renumbered: the first letter names the stage that reports
+// them (L lexer, P parser, V validators, H history, S suppression comments, D mod
+// descriptors) and the number is assigned in order.
+package codes
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// Code is the stable identifier of a kind of diagnostic, e.g. "P0003".
+type Code string
+
+const (
+	// Lexer
+	UnexpectedCharacter Code = "L0001"
+	SmartQuote          Code = "L0002"
+
+	// Parser
+	UnexpectedEOF        Code = "P0001"
+	UnexpectedToken      Code = "P0002"
+	ExpectedField        Code = "P0003"
+	ExpectedOperator     Code = "P0004"
+	ExpectedValue        Code = "P0005"
+	UnexpectedBlockToken Code = "P0006"
+	SkippedSyntax        Code = "P0007"
+	RecoveryFailed       Code = "P0008"
+	InvalidQuotedString  Code = "P0009"
+	NumberPrecisionLoss  Code = "P0010"
+	MissingClosingBrace  Code = "P0011"
+	DoubleEquals         Code = "P0012"
+
+	// Validators
+	RepeatedKey   Code = "V0001"
+	DeprecatedKey Code = "V0002"
+	UnreadFile    Code = "V0003"
+	WrongEncoding Code = "V0004"
+
+	// History
+	InvalidHistoryDate Code = "H0001"
+
+	// Suppression comments
+	UnusedSuppression  Code = "S0001"
+	InvalidSuppression Code = "S0002"
+
+	// Mod descriptors
+	MissingDescriptorField  Code = "D0001"
+	InvalidSupportedVersion Code = "D0002"
+	UnsupportedGameVersion  Code = "D0003"
+	MissingModPath          Code = "D0004"
+	MissingThumbnail        Code = "D0005"
+)
+
+// Entry documents a diagnostic code.
+type Entry struct {
+	Code     Code              `json:"code"`
+	Title    string            `json:"title"`
+	Severity severity.Severity `json:"severity"`
+	// Explanation describes the problem and how to fix it.
+	Explanation string `json:"explanation"`
+	// Example is a snippet of script that triggers the diagnostic.
+	Example string `json:"example"`
+}
+
+var catalog = map[Code]*Entry{
+	UnexpectedCharacter: {
+		Title:    "Unexpected character",
+		Severity: severity.Critical,
+		Explanation: "The file contains a character that can't start any token of the Paradox script " +
+			"syntax, such as a stray backslash or a control character. The game silently skips it, " +
+			"which can shift the meaning of the surrounding code. Remove the character, or quote " +
+			"the value if it is part of a string.",
+		Example: "name = Jarl\\Erik",
+	},
+	SmartQuote: {
+		Title:    "Typographic quote",
+		Severity: severity.Critical,
+		Explanation: "The file contains a typographic double quote (“, ” or „) instead of a straight " +
+			"quote, usually because the text was pasted from a word processor. The game doesn't " +
+			"recognize it as a quote, so the string isn't read as one. Replace it with '\"'; " +
+			"--fix does it automatically.",
+		Example: "name = “Erik”",
+	},
+	UnexpectedEOF: {
+		Title:    "Unexpected end of file",
+		Severity: severity.Error,
+		Explanation: "The file ends in the middle of a field: a key without an operator or an operator " +
+			"without a value. Complete or remove the last field. Blocks left open at the end of the " +
+			"file are reported as P0011.",
+		Example: "brave = {\n\tcategory = personality\n}\ncowardly =",
+	},
+	UnexpectedToken: {
+		Title:    "Unexpected token",
+		Severity: severity.Error,
+		Explanation: "The parser expected a specific token, such as the closing brace of a block, " +
+			"and found something else. Check for a missing or extra brace or operator before it.",
+		Example: "color = { 255 255 255 = = = = = = = = = = = = }",
+	},
+	ExpectedField: {
+		Title:    "Expected a field",
+		Severity: severity.Error,
+		Explanation: "A list of fields (the top level of a file or a block of key-value pairs) contains " +
+			"something that can't start a field. Fields start with a key: a word, a date or a number. " +
+			"Stray operators, quoted strings or extra closing braces trigger this error.",
+		Example: "brave = {\n\tcategory = personality\n}\n}",
+	},
+	ExpectedOperator: {
+		Title:    "Expected an operator",
+		Severity: severity.Error,
+		Explanation: "A key must be followed by an operator: '=', '?=', or a comparison such as '>='. " +
+			"This usually means a value lost its key, or two keys were written without an operator between them.",
+		Example: "brave = {\n\tcost = 10\n\tcategory personality\n}",
+	},
+	ExpectedValue: {
+		Title:    "Expected a value",
+		Severity: severity.Error,
+		Explanation: "An operator must be followed by a value: a word, a number, a boolean, a date, a " +
+			"quoted string or a block. Check for a doubled operator or a misplaced brace.",
+		Example: "brave = {\n\tcost = = 10\n}",
+	},
+	UnexpectedBlockToken: {
+		Title:    "Unexpected token in block",
+		Severity: severity.Error,
+		Explanation: "A block holds either fields (\"key = value\") or a list of values (\"{ a b c }\"). " +
+			"This token can start neither, for example an operator right after the opening brace.",
+		Example: "color = { = 255 255 255 }",
+	},
+	SkippedSyntax: {
+		Title:    "Skipped invalid syntax",
+		Severity: severity.Warning,
+		Explanation: "After a syntax error the parser skips tokens until it finds a place where parsing " +
+			"can resume. The skipped tokens are ignored, as the game would ignore them; fix the error " +
+			"reported before this warning.",
+		Example: "brave = {\n\tcost = = 10\n}",
+	},
+	RecoveryFailed: {
+		Title:    "Failed to recover from a syntax error",
+		Severity: severity.Error,
+		Explanation: "After a syntax error the parser could not find a place to resume parsing within " +
+			"a reasonable number of tokens, so the rest of the block is not checked. Fix the error " +
+			"reported before this one.",
+		Example: "brave = { = = = = = = = = = = = }",
+	},
+	InvalidQuotedString: {
+		Title:    "Invalid quoted string",
+		Severity: severity.Error,
+		Explanation: "A quoted string contains an invalid escape sequence. The original text is kept, " +
+			"but the game may read it differently. Escape backslashes as \"\\\\\".",
+		Example: "name = \"Jarl \\q\"",
+	},
+	NumberPrecisionLoss: {
+		Title:    "Number loses precision",
+		Severity: severity.Warning,
+		Explanation: "The game stores script numbers as fixed-point values with three decimals, so " +
+			"any further decimals are dropped. Round the number to thousandths.",
+		Example: "cost = 0.12345",
+	},
+	MissingClosingBrace: {
+		Title:    "Missing closing brace",
+		Severity: severity.Error,
+		Explanation: "A block is still open at the end of the file, so every field after its opening " +
+			"brace belongs to it. The brace is often missing several lines above the end of the " +
+			"file; --fix closes the block at the end of the file, check that this is where it should end.",
+		Example: "brave = {\n\tcategory = personality\n",
+	},
+	DoubleEquals: {
+		Title:    "Comparison used as assignment",
+		Severity: severity.Warning,
+		Explanation: "The comparison operator '==' is followed by a block or a boolean, which can't be " +
+			"compared: '=' is meant. Replace the operator; --fix does it automatically.",
+		Example: "is_valid == {\n\tis_adult == yes\n}",
+	},
+	RepeatedKey: {
+		Title:    "Repeated key",
+		Severity: severity.Warning,
+		Explanation: "A key that takes a single value appears more than once in the same block. Only " +
+			"one of the definitions takes effect (which one depends on the key), so the others are " +
+			"dead code or a copy-paste mistake. Remove or merge the duplicates.",
+		Example: "70027 = {\n\tname = Eric\n\tname = Erik\n}",
+	},
+	DeprecatedKey: {
+		Title:    "Deprecated key",
+		Severity: severity.Warning,
+		Explanation: "The key was renamed by a game update and the old name is no longer read. The " +
+			"renames are given with \"gock3 parse --renames\", a JSON object mapping each deprecated " +
+			"key to its replacement; --fix renames the keys. The example assumes old_key is renamed to new_key.",
+		Example: "trait = {\n\told_key = yes\n}",
+	},
+	UnreadFile: {
+		Title:    "File not read by the game",
+		Severity: severity.Warning,
+		Explanation: "The file lies in a folder the game doesn't load, such as the root of the mod, " +
+			"a misspelled top-level folder or directly in common/ instead of one of its subfolders. " +
+			"Its content has no effect; move it to the folder it belongs to. The example is checked " +
+			"as a file at the root of the mod.",
+		Example: "brave = {\n\tmartial = 2\n}",
+	},
+	WrongEncoding: {
+		Title:    "Wrong file encoding",
+		Severity: severity.Error,
+		Explanation: "The game reads its files as UTF-8, and localization files only if they start " +
+			"with a byte order mark (UTF-8 with BOM). A file saved in another encoding, such as " +
+			"Windows-1252, shows garbled names, and a localization file without a BOM is ignored. " +
+			"Save the file in the encoding its folder expects.",
+		Example: "name = \"Caf\xe9\"",
+	},
+	InvalidHistoryDate: {
+		Title:    "Invalid history date",
+		Severity: severity.Error,
+		Explanation: "A date-keyed history block has a date that doesn't exist, such as a 13th month or " +
+			"a 31st of a 30-day month. The game has no leap years, so February always has 28 days.",
+		Example: "70027 = {\n\t1066.2.29 = { death = yes }\n}",
+	},
+	UnusedSuppression: {
+		Title:    "Unused suppression comment",
+		Severity: severity.Warning,
+		Explanation: "A \"# gock3:ignore\" comment doesn't suppress any diagnostic, usually because the " +
+			"problem it silenced was fixed or the code moved. Remove the comment so it doesn't hide " +
+			"future problems.",
+		Example: "# gock3:ignore P0010\ncost = 10",
+	},
+	InvalidSuppression: {
+		Title:    "Invalid suppression comment",
+		Severity: severity.Warning,
+		Explanation: "A comment starting with \"gock3:\" is not a known directive or names an unknown code. " +
+			"The directives are \"ignore\" (next line), \"ignore-block\" (next field with its block) " +
+			"and \"ignore-file\", each followed by the codes to ignore, or none to ignore every code.",
+		Example: "# gock3:ignore-line P0010\ncost = 0.12345",
+	},
+	MissingDescriptorField: {
+		Title:    "Missing descriptor field",
+		Severity: severity.Error,
+		Explanation: "A mod descriptor must have a name, a version and a supported_version. The .mod " +
+			"file read by the launcher also needs the path of the mod folder, which a descriptor.mod " +
+			"inside the mod folder doesn't.",
+		Example: "name = \"Better Traits\"\nversion = \"1.2\"",
+	},
+	InvalidSupportedVersion: {
+		Title:    "Invalid supported_version",
+		Severity: severity.Error,
+		Explanation: "The supported_version of a mod descriptor is a game version made of numbers " +
+			"separated by dots, where the last parts can be '*' wildcards, such as \"1.12.*\". The " +
+			"launcher can't tell which game versions the mod supports otherwise.",
+		Example: "name = \"Better Traits\"\nversion = \"1.2\"\nsupported_version = \"1.x.*\"",
+	},
+	UnsupportedGameVersion: {
+		Title:    "Unsupported game version",
+		Severity: severity.Warning,
+		Explanation: "The supported_version of the mod descriptor doesn't match the game version the " +
+			"mod is checked against, so the launcher warns that the mod is out of date. Update the " +
+			"mod and its supported_version. The example assumes game version 1.12.4.",
+		Example: "name = \"Better Traits\"\nversion = \"1.2\"\nsupported_version = \"1.9.*\"",
+	},
+	MissingModPath: {
+		Title:    "Missing mod folder",
+		Severity: severity.Error,
+		Explanation: "The path of a .mod file, relative to the user directory of the game (the one " +
+			"containing the \"mod\" folder) or absolute, doesn't lead to a folder, so the launcher " +
+			"can't load the mod.",
+		Example: "name = \"Better Traits\"\nversion = \"1.2\"\nsupported_version = \"1.12.*\"\npath = \"mod/missing_mod\"",
+	},
+	MissingThumbnail: {
+		Title:    "Missing thumbnail",
+		Severity: severity.Warning,
+		Explanation: "The picture of a mod descriptor, relative to the mod folder, doesn't exist, so " +
+			"the launcher shows no thumbnail for the mod.",
+		Example: "name = \"Better Traits\"\nversion = \"1.2\"\nsupported_version = \"1.12.*\"\npicture = \"thumbnail.png\"",
+	},
+}
+
+func init() {
+	for code, entry := range catalog {
+		entry.Code = code
+	}
+}
+
+// Lookup returns the catalog entry of a code. Codes are matched case-insensitively.
+func Lookup(code Code) (*Entry, bool) {
+	entry, ok := catalog[Code(strings.ToUpper(string(code)))]
+	return entry, ok
+}
+
+// All returns every catalog entry, sorted by code.
+func All() []*Entry {
+	entries := make([]*Entry, 0, len(catalog))
+	for _, entry := range catalog {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Code < entries[j].Code
+	})
+	return entries
+}
+
+// Severity returns the default severity of a code, or severity.Error for unknown codes.
+func (c Code) Severity() severity.Severity {
+	if entry, ok := catalog[c]; ok {
+		return entry.Severity
+	}
+	return severity.Error
+}
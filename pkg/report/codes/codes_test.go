@@ -0,0 +1,113 @@
+package codes_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/descriptor"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/filetypes"
+	"github.com/unLomTrois/gock3/pkg/history"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/suppress"
+	"github.com/unLomTrois/gock3/pkg/validator"
+)
+
+// exampleRenames is the rename table of the DeprecatedKey example.
+var exampleRenames = map[string]string{"old_key": "new_key"}
+
+// examplePath is the path of the examples relative to the mod root, at its root.
+const examplePath = "example.txt"
+
+// exampleGameVersion is the game version descriptor examples are checked against.
+const exampleGameVersion = "1.12.4"
+
+var codePattern = regexp.MustCompile(`^[LPVHSD][0-9]{4}$`)
+
+func TestCatalog(t *testing.T) {
+	entries := codes.All()
+	if len(entries) == 0 {
+		t.Fatal("empty catalog")
+	}
+
+	for i, entry := range entries {
+		if !codePattern.MatchString(string(entry.Code)) {
+			t.Errorf("code %q doesn't match %s", entry.Code, codePattern)
+		}
+		if entry.Title == "" || entry.Explanation == "" || entry.Example == "" {
+			t.Errorf("%s: title, explanation and example are required", entry.Code)
+		}
+		if i > 0 && entries[i-1].Code >= entry.Code {
+			t.Errorf("entries are not sorted: %s before %s", entries[i-1].Code, entry.Code)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	entry, ok := codes.Lookup("p0003")
+	if !ok || entry.Code != codes.ExpectedField {
+		t.Errorf("Lookup(p0003) = %v, %v, want the ExpectedField entry", entry, ok)
+	}
+
+	if _, ok := codes.Lookup("P9999"); ok {
+		t.Errorf("Lookup(P9999) found an entry")
+	}
+}
+
+// TestExamples checks that the example of every entry triggers its code.
+func TestExamples(t *testing.T) {
+	for _, entry := range codes.All() {
+		t.Run(string(entry.Code), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "example.txt")
+			if err := os.WriteFile(path, []byte(entry.Example), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			file := files.NewParadoxTxtFile(path, files.Mod)
+			tree, diagnostics, err := parser.ParseParadoxFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			typeDiagnostics, err := filetypes.Default.Check(file, examplePath, tree)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diagnostics = append(diagnostics, typeDiagnostics...)
+			diagnostics = append(diagnostics, validator.DuplicateKeys(tree.Block, validator.CharacterHistory)...)
+			diagnostics = append(diagnostics, validator.DeprecatedKeys(tree.Block, exampleRenames)...)
+			if len(tree.Block.Values) > 0 {
+				_, timelineDiagnostics := history.NewTimeline(tree.Block.Values[0])
+				diagnostics = append(diagnostics, timelineDiagnostics...)
+			}
+			if d, descriptorDiagnostics, err := descriptor.Parse(path); err == nil {
+				diagnostics = append(diagnostics, descriptorDiagnostics...)
+				diagnostics = append(diagnostics, d.Check(exampleGameVersion)...)
+			}
+			var checked []codes.Code
+			for _, e := range codes.All() {
+				checked = append(checked, e.Code)
+			}
+			diagnostics = suppress.Apply(tree, diagnostics, checked)
+
+			if !hasCode(diagnostics, entry.Code) {
+				t.Errorf("example doesn't trigger %s, got:", entry.Code)
+				for _, diag := range diagnostics {
+					t.Log(diag.Error())
+				}
+			}
+		})
+	}
+}
+
+func hasCode(diagnostics []*report.DiagnosticItem, code codes.Code) bool {
+	for _, diag := range diagnostics {
+		if diag.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+)
+
+const noteOmitted = "%d more diagnostic(s) in this file omitted"
+
+// Collector gathers diagnostics, possibly from several goroutines. Identical
+// diagnostics are kept once, and the result is sorted by file, line and column,
+// so it doesn't depend on the order the diagnostics were added in.
+type Collector struct {
+	mu    sync.Mutex
+	items []*DiagnosticItem
+	seen  map[diagnosticKey]struct{}
+
+	// maxPerFile caps the diagnostics returned per file, 0 for no cap.
+	maxPerFile int
+}
+
+// diagnosticKey identifies identical diagnostics.
+type diagnosticKey struct {
+	file   files.PathTableIndex
+	line   uint32
+	column uint16
+	length int
+	code   codes.Code
+	msg    string
+	// related lists the related locations, which tell apart e.g. the missing braces
+	// of nested blocks
+	related string
+}
+
+// NewCollector creates a Collector returning at most maxPerFile diagnostics per file,
+// or all of them if maxPerFile is 0.
+func NewCollector(maxPerFile int) *Collector {
+	return &Collector{
+		seen:       make(map[diagnosticKey]struct{}),
+		maxPerFile: maxPerFile,
+	}
+}
+
+// Add adds diagnostics, ignoring the ones already collected.
+func (c *Collector) Add(diagnostics ...*DiagnosticItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, diag := range diagnostics {
+		key := diagnosticKey{code: diag.Code, msg: diag.Msg}
+		if diag.Pointer != nil {
+			key.file = diag.Pointer.Loc.GetIdx()
+			key.line = diag.Pointer.Loc.Line
+			key.column = diag.Pointer.Loc.Column
+			key.length = diag.Pointer.Length
+		}
+		for _, related := range diag.Related {
+			loc := related.Pointer.Loc
+			key.related += fmt.Sprintf("%v:%d:%d;", loc.GetIdx(), loc.Line, loc.Column)
+		}
+		if _, ok := c.seen[key]; ok {
+			continue
+		}
+		c.seen[key] = struct{}{}
+		c.items = append(c.items, diag)
+	}
+}
+
+// Len returns the number of collected diagnostics.
+func (c *Collector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Summary counts every collected diagnostic, including the ones omitted by the cap.
+func (c *Collector) Summary() Summary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Summarize(c.items)
+}
+
+// Diagnostics returns the collected diagnostics sorted by file, line and column. Past
+// the cap of a file, the last returned diagnostic of the file notes how many were omitted.
+func (c *Collector) Diagnostics() []*DiagnosticItem {
+	c.mu.Lock()
+	items := append([]*DiagnosticItem{}, c.items...)
+	c.mu.Unlock()
+
+	sortDiagnostics(items)
+	if c.maxPerFile <= 0 {
+		return items
+	}
+
+	result := make([]*DiagnosticItem, 0, len(items))
+	for start := 0; start < len(items); {
+		end := start
+		for end < len(items) && pathOf(items[end]) == pathOf(items[start]) {
+			end++
+		}
+
+		if end-start <= c.maxPerFile {
+			result = append(result, items[start:end]...)
+		} else {
+			kept := items[start : start+c.maxPerFile]
+			result = append(result, kept[:len(kept)-1]...)
+
+			// Copied, the diagnostic may be shared with other collectors
+			last := *kept[len(kept)-1]
+			last.Notes = append(append([]string{}, last.Notes...), fmt.Sprintf(noteOmitted, end-start-c.maxPerFile))
+			result = append(result, &last)
+		}
+		start = end
+	}
+	return result
+}
+
+// sortDiagnostics sorts diagnostics by file, line, column, code and message.
+func sortDiagnostics(items []*DiagnosticItem) {
+	paths := make(map[*DiagnosticItem]string, len(items))
+	for _, diag := range items {
+		paths[diag] = pathOf(diag)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if paths[a] != paths[b] {
+			return paths[a] < paths[b]
+		}
+		la, lb := locOf(a), locOf(b)
+		if la.line != lb.line {
+			return la.line < lb.line
+		}
+		if la.column != lb.column {
+			return la.column < lb.column
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.Msg < b.Msg
+	})
+}
+
+type position struct {
+	line   uint32
+	column uint16
+}
+
+func locOf(diag *DiagnosticItem) position {
+	if diag.Pointer == nil {
+		return position{}
+	}
+	return position{line: diag.Pointer.Loc.Line, column: diag.Pointer.Loc.Column}
+}
+
+func pathOf(diag *DiagnosticItem) string {
+	if diag.Pointer == nil {
+		return ""
+	}
+	// Full paths, files with the same relative path in several roots are different
+	path, _ := diag.Pointer.Loc.GetIdx().Fullpath()
+	return path
+}
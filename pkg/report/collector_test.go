@@ -0,0 +1,110 @@
+package report_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// locIn returns a location in a new file of the given name.
+func locIn(t *testing.T, dir, name string) tokens.Loc {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return *tokens.LocFromParadoxFile(files.NewParadoxTxtFile(path, files.Mod))
+}
+
+func at(loc tokens.Loc, line uint32, column uint16) tokens.Loc {
+	loc.Line, loc.Column = line, column
+	return loc
+}
+
+func TestCollector(t *testing.T) {
+	dir := t.TempDir()
+	a, b := locIn(t, dir, "a.txt"), locIn(t, dir, "b.txt")
+
+	c := report.NewCollector(0)
+	var wg sync.WaitGroup
+	// The same diagnostics from concurrent goroutines, in different orders
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(reverse bool) {
+			defer wg.Done()
+			diagnostics := []*report.DiagnosticItem{
+				report.FromLoc(at(b, 1, 1), codes.ExpectedField, "b 1:1"),
+				report.FromLoc(at(a, 2, 5), codes.ExpectedValue, "a 2:5"),
+				report.FromLoc(at(a, 2, 1), codes.ExpectedField, "a 2:1"),
+				report.FromLoc(at(a, 10, 1), codes.ExpectedField, "a 10:1"),
+			}
+			if reverse {
+				for i, j := 0, len(diagnostics)-1; i < j; i, j = i+1, j-1 {
+					diagnostics[i], diagnostics[j] = diagnostics[j], diagnostics[i]
+				}
+			}
+			for _, diag := range diagnostics {
+				c.Add(diag)
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+
+	var got []string
+	for _, diag := range c.Diagnostics() {
+		got = append(got, diag.Msg)
+	}
+	want := []string{"a 2:1", "a 2:5", "a 10:1", "b 1:1"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCollector_MaxPerFile(t *testing.T) {
+	dir := t.TempDir()
+	a, b := locIn(t, dir, "a.txt"), locIn(t, dir, "b.txt")
+
+	c := report.NewCollector(2)
+	for line := uint32(1); line <= 5; line++ {
+		c.Add(report.FromLoc(at(a, line, 1), codes.ExpectedField, "field"))
+	}
+	shared := report.FromLoc(at(b, 1, 1), codes.ExpectedField, "field")
+	c.Add(shared)
+
+	diagnostics := c.Diagnostics()
+	if len(diagnostics) != 3 {
+		t.Fatalf("got %d diagnostics, want 2 in a.txt and 1 in b.txt", len(diagnostics))
+	}
+	if notes := diagnostics[1].Notes; len(notes) != 1 || notes[0] != "3 more diagnostic(s) in this file omitted" {
+		t.Errorf("notes of the last diagnostic of a.txt = %v", notes)
+	}
+	if len(diagnostics[2].Notes) != 0 {
+		t.Errorf("b.txt is under the cap, got notes %v", diagnostics[2].Notes)
+	}
+	if got := c.Summary().AtLeast(0); got != 6 {
+		t.Errorf("summary counts %d diagnostics, want all 6", got)
+	}
+}
+
+func TestCollector_RelatedTellApart(t *testing.T) {
+	dir := t.TempDir()
+	a := locIn(t, dir, "a.txt")
+
+	c := report.NewCollector(0)
+	for _, opened := range []uint32{1, 2, 2} {
+		diag := report.FromLoc(at(a, 5, 1), codes.MissingClosingBrace, "missing brace")
+		diag.Related = []*report.RelatedLocation{{Pointer: &report.DiagnosticPointer{Loc: at(a, opened, 9)}, Msg: "block opened here"}}
+		c.Add(diag)
+	}
+	if got := c.Len(); got != 2 {
+		t.Errorf("got %d diagnostics, want the 2 distinct ones", got)
+	}
+}
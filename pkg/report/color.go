@@ -0,0 +1,46 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ColorMode controls whether the text reporter colors its output.
+type ColorMode string
+
+const (
+	// ColorAuto colors the output of terminals, unless the NO_COLOR environment variable is set.
+	ColorAuto   ColorMode = "auto"
+	ColorAlways ColorMode = "always"
+	ColorNever  ColorMode = "never"
+)
+
+// ParseColorMode parses the value of a --color flag.
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(s); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown color mode %q (expected auto, always or never)", s)
+	}
+}
+
+// Enabled reports whether output written to w should be colored.
+// See https://no-color.org for NO_COLOR.
+func (m ColorMode) Enabled(w io.Writer) bool {
+	switch m {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
@@ -5,14 +5,48 @@ import (
 
 	"github.com/unLomTrois/gock3/pkg/ast"
 	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
 	"github.com/unLomTrois/gock3/pkg/report/severity"
 	"github.com/unLomTrois/gock3/pkg/tokens"
 )
 
 type DiagnosticItem struct {
 	Severity severity.Severity
-	Pointer  *DiagnosticPointer
-	Msg      string
+	// Code is the stable identifier of the kind of diagnostic, see the codes package.
+	Code    codes.Code
+	Pointer *DiagnosticPointer
+	Msg     string
+	// Related holds other locations relevant to the diagnostic, such as a previous definition.
+	Related []*RelatedLocation
+	// Notes add context to the message, Help suggests how to fix the problem.
+	Notes []string
+	Help  string
+	// Fixes are machine-applicable corrections, see the fix package.
+	Fixes []*Fix
+}
+
+// Fix is a correction of a diagnostic made of one or more text edits, applied together.
+type Fix struct {
+	Msg   string
+	Edits []*Edit
+}
+
+// Edit replaces Length bytes starting at Loc with Text. A zero Length inserts Text.
+type Edit struct {
+	Loc    tokens.Loc
+	Length int
+	Text   string
+}
+
+// ReplaceToken returns an edit replacing the text of a token.
+func ReplaceToken(token *tokens.Token, text string) *Edit {
+	return &Edit{Loc: token.Loc, Length: len(token.Value), Text: text}
+}
+
+// RelatedLocation is a secondary location of a diagnostic with a label explaining its role.
+type RelatedLocation struct {
+	Pointer *DiagnosticPointer
+	Msg     string
 }
 
 type DiagnosticPointer struct {
@@ -21,20 +55,23 @@ type DiagnosticPointer struct {
 }
 
 func (d *DiagnosticItem) Error() string {
-	return fmt.Sprintf("%s: %s", d.Severity, d.Msg)
+	return fmt.Sprintf("%s[%s]: %s", d.Severity, d.Code, d.Msg)
 }
 
-func NewDiagnosticItem(severity severity.Severity, msg string, pointer *DiagnosticPointer) *DiagnosticItem {
+// NewDiagnosticItem creates a DiagnosticItem with the default severity of its code.
+func NewDiagnosticItem(code codes.Code, msg string, pointer *DiagnosticPointer) *DiagnosticItem {
 	return &DiagnosticItem{
-		Severity: severity,
+		Severity: code.Severity(),
+		Code:     code,
 		Msg:      msg,
 		Pointer:  pointer,
 	}
 }
 
-func FromToken(token *tokens.Token, severity severity.Severity, msg string) *DiagnosticItem {
+func FromToken(token *tokens.Token, code codes.Code, msg string) *DiagnosticItem {
 	return &DiagnosticItem{
-		Severity: severity,
+		Severity: code.Severity(),
+		Code:     code,
 		Msg:      msg,
 		Pointer: &DiagnosticPointer{
 			Loc:    token.Loc,
@@ -43,11 +80,12 @@ func FromToken(token *tokens.Token, severity severity.Severity, msg string) *Dia
 	}
 }
 
-func FromFile(file files.ParadoxFile, severity severity.Severity, msg string) *DiagnosticItem {
+func FromFile(file files.ParadoxFile, code codes.Code, msg string) *DiagnosticItem {
 	loc := tokens.LocFromParadoxFile(file)
 
 	return &DiagnosticItem{
-		Severity: severity,
+		Severity: code.Severity(),
+		Code:     code,
 		Msg:      msg,
 		Pointer: &DiagnosticPointer{
 			Loc:    *loc,
@@ -56,11 +94,12 @@ func FromFile(file files.ParadoxFile, severity severity.Severity, msg string) *D
 	}
 }
 
-func FromBlock(file_block *ast.FileBlock, severity severity.Severity, msg string) *DiagnosticItem {
+func FromBlock(file_block *ast.FileBlock, code codes.Code, msg string) *DiagnosticItem {
 	loc := file_block.Loc
 
 	return &DiagnosticItem{
-		Severity: severity,
+		Severity: code.Severity(),
+		Code:     code,
 		Msg:      msg,
 		Pointer: &DiagnosticPointer{
 			Loc:    loc,
@@ -72,9 +111,10 @@ func FromBlock(file_block *ast.FileBlock, severity severity.Severity, msg string
 // FromLoc creates a new DiagnosticItem from a loc
 // Primary used in cases when you know the loc but you don't know the token
 // Happens in Lexer
-func FromLoc(loc tokens.Loc, severity severity.Severity, msg string) *DiagnosticItem {
+func FromLoc(loc tokens.Loc, code codes.Code, msg string) *DiagnosticItem {
 	return &DiagnosticItem{
-		Severity: severity,
+		Severity: code.Severity(),
+		Code:     code,
 		Msg:      msg,
 		Pointer: &DiagnosticPointer{
 			Loc:    loc,
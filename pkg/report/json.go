@@ -0,0 +1,109 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// jsonDiagnostic is the serialized form of a DiagnosticItem.
+type jsonDiagnostic struct {
+	Severity severity.Severity `json:"severity"`
+	Code     codes.Code        `json:"code,omitempty"`
+	Message  string            `json:"message"`
+	File     string            `json:"file"`
+	// Root is the name of the game or mod the file was loaded from, if known, and
+	// Path the file relative to it
+	Root    string         `json:"root,omitempty"`
+	Path    string         `json:"path,omitempty"`
+	Line    uint32         `json:"line"`
+	Column  uint16         `json:"column"`
+	Length  int            `json:"length"`
+	Related []*jsonRelated `json:"related,omitempty"`
+	Notes   []string       `json:"notes,omitempty"`
+	Help    string         `json:"help,omitempty"`
+}
+
+// jsonRelated is the serialized form of a RelatedLocation.
+type jsonRelated struct {
+	Message string `json:"message"`
+	File    string `json:"file"`
+	Line    uint32 `json:"line"`
+	Column  uint16 `json:"column"`
+	Length  int    `json:"length"`
+}
+
+func toJSONDiagnostic(diag *DiagnosticItem) *jsonDiagnostic {
+	file, _ := diag.Pointer.Loc.GetIdx().Fullpath()
+	item := &jsonDiagnostic{
+		Severity: diag.Severity,
+		Code:     diag.Code,
+		Message:  diag.Msg,
+		File:     file,
+		Line:     diag.Pointer.Loc.Line,
+		Column:   diag.Pointer.Loc.Column,
+		Length:   diag.Pointer.Length,
+		Notes:    diag.Notes,
+		Help:     diag.Help,
+	}
+	if root, ok := diag.Pointer.Loc.GetIdx().Root(); ok {
+		item.Root = root.Name
+		item.Path, _ = diag.Pointer.Loc.Pathname()
+	}
+	for _, related := range diag.Related {
+		relatedFile, _ := related.Pointer.Loc.GetIdx().Fullpath()
+		item.Related = append(item.Related, &jsonRelated{
+			Message: related.Msg,
+			File:    relatedFile,
+			Line:    related.Pointer.Loc.Line,
+			Column:  related.Pointer.Loc.Column,
+			Length:  related.Pointer.Length,
+		})
+	}
+	return item
+}
+
+// JSONReporter writes all diagnostics as a single JSON array.
+type JSONReporter struct {
+	w io.Writer
+}
+
+// NewJSONReporter creates a JSONReporter writing to w.
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{w: w}
+}
+
+func (r *JSONReporter) Report(diagnostics []*DiagnosticItem) error {
+	items := make([]*jsonDiagnostic, 0, len(diagnostics))
+	for _, diag := range diagnostics {
+		items = append(items, toJSONDiagnostic(diag))
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	return enc.Encode(items)
+}
+
+// JSONLinesReporter writes one JSON object per diagnostic per line, for streaming consumers.
+type JSONLinesReporter struct {
+	w io.Writer
+}
+
+// NewJSONLinesReporter creates a JSONLinesReporter writing to w.
+func NewJSONLinesReporter(w io.Writer) *JSONLinesReporter {
+	return &JSONLinesReporter{w: w}
+}
+
+func (r *JSONLinesReporter) Report(diagnostics []*DiagnosticItem) error {
+	enc := json.NewEncoder(r.w)
+	enc.SetEscapeHTML(false)
+	for _, diag := range diagnostics {
+		if err := enc.Encode(toJSONDiagnostic(diag)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/unLomTrois/gock3/pkg/cache"
+)
+
+// Output formats understood by NewReporter.
+const (
+	FormatText      = "text"
+	FormatJSON      = "json"
+	FormatJSONLines = "jsonl"
+	FormatSARIF     = "sarif"
+)
+
+// Formats lists the output formats understood by NewReporter.
+var Formats = []string{FormatText, FormatJSON, FormatJSONLines, FormatSARIF}
+
+// Reporter writes diagnostics in a specific output format.
+type Reporter interface {
+	Report(diagnostics []*DiagnosticItem) error
+}
+
+// ReporterOptions configures the reporters created by NewReporter.
+type ReporterOptions struct {
+	// Root is the mod root directory file locations are made relative to, if set.
+	Root string
+	// Color controls the colors of the text format.
+	Color ColorMode
+	// AbsolutePaths shows full paths in the text format instead of paths relative to
+	// the game or mod of the files.
+	AbsolutePaths bool
+	// Cache holds the files shown by the text format and read for the columns of the
+	// SARIF format, e.g. the cache the files were parsed through. The reporters have
+	// a cache of their own if it's nil.
+	Cache *cache.ContentCache
+}
+
+// NewReporter returns the built-in Reporter for the given format, writing to w.
+func NewReporter(format string, w io.Writer, opts ReporterOptions) (Reporter, error) {
+	switch format {
+	case FormatText:
+		r := NewTextReporter(w, opts.Color)
+		r.AbsolutePaths = opts.AbsolutePaths
+		if opts.Cache != nil {
+			r.cache = opts.Cache
+		}
+		return r, nil
+	case FormatJSON:
+		return NewJSONReporter(w), nil
+	case FormatJSONLines:
+		return NewJSONLinesReporter(w), nil
+	case FormatSARIF:
+		r := NewSARIFReporter(w, opts.Root)
+		if opts.Cache != nil {
+			r.cache = opts.Cache
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected one of %v)", format, Formats)
+	}
+}
@@ -0,0 +1,341 @@
+package report_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+type jsonDiagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	File     string `json:"file"`
+	Line     uint32 `json:"line"`
+	Column   uint16 `json:"column"`
+	Length   int    `json:"length"`
+}
+
+// testDiagnostics returns two diagnostics located in a temporary file.
+func testDiagnostics(t *testing.T) (string, []*report.DiagnosticItem) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "00_traits.txt")
+	if err := os.WriteFile(path, []byte("brave = {\n\tcost = 1.23456\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loc := tokens.LocFromParadoxFile(files.NewParadoxTxtFile(path, files.Mod))
+	second := *loc
+	second.Line = 2
+	second.Column = 12
+
+	return path, []*report.DiagnosticItem{
+		report.FromLoc(*loc, codes.UnexpectedEOF, "Missing closing brace"),
+		{
+			Severity: severity.Warning,
+			Code:     codes.NumberPrecisionLoss,
+			Msg:      "Number has more than three decimals",
+			Pointer:  &report.DiagnosticPointer{Loc: second, Length: 7},
+		},
+	}
+}
+
+func TestNewReporter(t *testing.T) {
+	for _, format := range report.Formats {
+		if _, err := report.NewReporter(format, &bytes.Buffer{}, report.ReporterOptions{}); err != nil {
+			t.Errorf("NewReporter(%q) error = %v", format, err)
+		}
+	}
+
+	if _, err := report.NewReporter("xml", &bytes.Buffer{}, report.ReporterOptions{}); err == nil {
+		t.Errorf("NewReporter(\"xml\") expected an error")
+	}
+}
+
+func TestTextReporter(t *testing.T) {
+	path, diagnostics := testDiagnostics(t)
+	diagnostics[1].Notes = []string{"the game stores numbers as fixed-point values"}
+	diagnostics[1].Help = "the game reads it as 1.234"
+
+	var buf bytes.Buffer
+	if err := report.NewTextReporter(&buf, report.ColorNever).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"error[P0001]: Missing closing brace",
+		" --> " + path + ":1:1",
+		"  |",
+		"1 | brave = {",
+		"  | ^",
+		"2 |     cost = 1.23456",
+		"",
+		"warning[P0010]: Number has more than three decimals",
+		" --> " + path + ":2:12",
+		"  |",
+		"1 | brave = {",
+		"2 |     cost = 1.23456",
+		"  |            ^^^^^^^",
+		"3 |",
+		"  = note: the game stores numbers as fixed-point values",
+		"  = help: the game reads it as 1.234",
+		"",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTextReporter_Related(t *testing.T) {
+	path, diagnostics := testDiagnostics(t)
+	opening := diagnostics[0].Pointer.Loc
+	opening.Column = 9
+
+	other := filepath.Join(filepath.Dir(path), "01_traits.txt")
+	if err := os.WriteFile(other, []byte("brave = {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	otherLoc := tokens.LocFromParadoxFile(files.NewParadoxTxtFile(other, files.Mod))
+
+	diag := diagnostics[1]
+	diag.Related = []*report.RelatedLocation{
+		{Pointer: &report.DiagnosticPointer{Loc: opening, Length: 1}, Msg: "block opened here"},
+		{Pointer: &report.DiagnosticPointer{Loc: *otherLoc, Length: 5}, Msg: "first defined here"},
+	}
+
+	var buf bytes.Buffer
+	if err := report.NewTextReporter(&buf, report.ColorNever).Report([]*report.DiagnosticItem{diag}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"warning[P0010]: Number has more than three decimals",
+		" --> " + path + ":2:12",
+		"  |",
+		"1 | brave = {",
+		"  |         - block opened here",
+		"2 |     cost = 1.23456",
+		"  |            ^^^^^^^",
+		"3 |",
+		" ::: " + other + ":1:1",
+		"  |",
+		"1 | brave = {",
+		"  | ----- first defined here",
+		"",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTextReporter_Color(t *testing.T) {
+	_, diagnostics := testDiagnostics(t)
+
+	var buf bytes.Buffer
+	if err := report.NewTextReporter(&buf, report.ColorAlways).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("expected ANSI escape codes with --color=always:\n%q", buf.String())
+	}
+
+	// A buffer is not a terminal
+	buf.Reset()
+	if err := report.NewTextReporter(&buf, report.ColorAuto).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("unexpected ANSI escape codes with --color=auto:\n%q", buf.String())
+	}
+}
+
+func TestColorMode(t *testing.T) {
+	for _, s := range []string{"auto", "always", "never"} {
+		if _, err := report.ParseColorMode(s); err != nil {
+			t.Errorf("ParseColorMode(%q) error = %v", s, err)
+		}
+	}
+	if _, err := report.ParseColorMode("yes"); err == nil {
+		t.Errorf("ParseColorMode(\"yes\") expected an error")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if report.ColorAuto.Enabled(os.Stdout) {
+		t.Errorf("NO_COLOR should disable colors in auto mode")
+	}
+	if !report.ColorAlways.Enabled(os.Stdout) {
+		t.Errorf("--color=always should override NO_COLOR")
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	path, diagnostics := testDiagnostics(t)
+
+	var buf bytes.Buffer
+	if err := report.NewJSONReporter(&buf).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []jsonDiagnostic
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+
+	want := []jsonDiagnostic{
+		{Severity: "error", Code: "P0001", Message: "Missing closing brace", File: path, Line: 1, Column: 1},
+		{Severity: "warning", Code: "P0010", Message: "Number has more than three decimals", File: path, Line: 2, Column: 12, Length: 7},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("diagnostic %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestJSONReporter_Related(t *testing.T) {
+	path, diagnostics := testDiagnostics(t)
+	diag := diagnostics[1]
+	diag.Related = []*report.RelatedLocation{{Pointer: diagnostics[0].Pointer, Msg: "block opened here"}}
+	diag.Notes = []string{"a note"}
+	diag.Help = "a help"
+
+	var buf bytes.Buffer
+	if err := report.NewJSONLinesReporter(&buf).Report([]*report.DiagnosticItem{diag}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Related []struct {
+			Message string `json:"message"`
+			File    string `json:"file"`
+			Line    uint32 `json:"line"`
+		} `json:"related"`
+		Notes []string `json:"notes"`
+		Help  string   `json:"help"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Related) != 1 || got.Related[0].Message != "block opened here" || got.Related[0].File != path || got.Related[0].Line != 1 {
+		t.Errorf("related = %+v", got.Related)
+	}
+	if len(got.Notes) != 1 || got.Notes[0] != "a note" || got.Help != "a help" {
+		t.Errorf("notes = %v, help = %q", got.Notes, got.Help)
+	}
+}
+
+func TestJSONReporter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := report.NewJSONReporter(&buf).Report(nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("got %q, want an empty array", buf.String())
+	}
+}
+
+func TestJSONLinesReporter(t *testing.T) {
+	_, diagnostics := testDiagnostics(t)
+
+	var buf bytes.Buffer
+	if err := report.NewJSONLinesReporter(&buf).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	var got []jsonDiagnostic
+	for scanner.Scan() {
+		var diag jsonDiagnostic
+		if err := json.Unmarshal(scanner.Bytes(), &diag); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, diag)
+	}
+
+	if len(got) != len(diagnostics) {
+		t.Fatalf("got %d lines, want %d", len(got), len(diagnostics))
+	}
+	if got[1].Severity != "warning" || got[1].Line != 2 {
+		t.Errorf("second line = %+v", got[1])
+	}
+}
+
+func TestSummary(t *testing.T) {
+	_, diagnostics := testDiagnostics(t)
+	diagnostics = append(diagnostics, report.NewDiagnosticItem(codes.UnexpectedCharacter, "character", diagnostics[0].Pointer))
+
+	summary := report.Summarize(diagnostics)
+	if got, want := summary.String(), "1 critical, 1 error, 1 warning, 0 info"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := summary.AtLeast(severity.Error); got != 2 {
+		t.Errorf("AtLeast(Error) = %d, want 2", got)
+	}
+	if got := summary.AtLeast(severity.Info); got != 3 {
+		t.Errorf("AtLeast(Info) = %d, want 3", got)
+	}
+}
+
+func TestTextReporter_Root(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "common", "traits", "00_traits.txt")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("brave = {\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := files.NewParadoxTxtFile(path, files.Mod)
+	file.SetRoot(files.Root{Name: "Better Traits", Path: dir})
+	diagnostics := []*report.DiagnosticItem{report.FromLoc(*tokens.LocFromParadoxFile(file), codes.UnexpectedEOF, "Missing closing brace")}
+
+	tests := []struct {
+		absolute bool
+		want     string
+	}{
+		{absolute: false, want: " --> [Better Traits] common/traits/00_traits.txt:1:1\n"},
+		{absolute: true, want: " --> " + path + ":1:1\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		r, err := report.NewReporter(report.FormatText, &buf, report.ReporterOptions{Color: report.ColorNever, AbsolutePaths: tt.absolute})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Report(diagnostics); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("absolute = %v: output doesn't contain %q:\n%s", tt.absolute, tt.want, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := report.NewJSONReporter(&buf).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	var items []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &items); err != nil {
+		t.Fatal(err)
+	}
+	if items[0]["file"] != path || items[0]["root"] != "Better Traits" || items[0]["path"] != "common/traits/00_traits.txt" {
+		t.Errorf("unexpected JSON location %v", items[0])
+	}
+}
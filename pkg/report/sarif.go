@@ -0,0 +1,282 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/unLomTrois/gock3/pkg/cache"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	// sarifRootID is the uriBaseId of file locations relative to the mod root.
+	sarifRootID = "MODROOT"
+	// sarifColumnKind is the unit of the columns of regions, as expected by code
+	// scanning services.
+	sarifColumnKind = "utf16CodeUnits"
+)
+
+// SARIF 2.1.0 log objects, limited to the properties gock3 emits.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                         `json:"tool"`
+	OriginalURIBaseIDs map[string]*sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	ColumnKind         string                            `json:"columnKind"`
+	Results            []*sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	InformationURI string       `json:"informationUri"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                       `json:"id"`
+	ShortDescription     *sarifMultiformatMessage     `json:"shortDescription,omitempty"`
+	FullDescription      *sarifMultiformatMessage     `json:"fullDescription,omitempty"`
+	DefaultConfiguration *sarifReportingConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifMultiformatMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifReportingConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID           codes.Code       `json:"ruleId,omitempty"`
+	RuleIndex        *int             `json:"ruleIndex,omitempty"`
+	Level            string           `json:"level"`
+	Message          sarifMessage     `json:"message"`
+	Locations        []*sarifLocation `json:"locations"`
+	RelatedLocations []*sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               *int                   `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   uint32 `json:"startLine"`
+	StartColumn int    `json:"startColumn"`
+	EndLine     uint32 `json:"endLine,omitempty"`
+	EndColumn   int    `json:"endColumn,omitempty"`
+}
+
+// SARIFReporter writes diagnostics as a SARIF 2.1.0 log, the format ingested by code
+// scanning services to annotate pull requests.
+type SARIFReporter struct {
+	w    io.Writer
+	root string
+	// cache holds the lines the columns of the lexer are converted on.
+	cache *cache.ContentCache
+}
+
+// NewSARIFReporter creates a SARIFReporter writing to w. Files under root are referenced
+// relative to it, other files (or all of them if root is empty) by absolute URIs.
+func NewSARIFReporter(w io.Writer, root string) *SARIFReporter {
+	if root != "" {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+	}
+	return &SARIFReporter{w: w, root: root, cache: cache.NewContentCache(0)}
+}
+
+func (r *SARIFReporter) Report(diagnostics []*DiagnosticItem) error {
+	run := &sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "gock3",
+			InformationURI: "https://github.com/unLomTrois/gock3",
+			Rules:          []*sarifRule{},
+		}},
+		ColumnKind: sarifColumnKind,
+		Results:    make([]*sarifResult, 0, len(diagnostics)),
+	}
+	if r.root != "" {
+		run.OriginalURIBaseIDs = map[string]*sarifArtifactLocation{
+			sarifRootID: {URI: fileURI(r.root) + "/"},
+		}
+	}
+
+	ruleIndex := make(map[codes.Code]int)
+	for _, diag := range diagnostics {
+		result := &sarifResult{
+			Level:     sarifLevel(diag.Severity),
+			Message:   sarifMessage{Text: sarifText(diag)},
+			Locations: []*sarifLocation{r.location(diag.Pointer, "")},
+		}
+
+		if diag.Code != "" {
+			index, ok := ruleIndex[diag.Code]
+			if !ok {
+				index = len(run.Tool.Driver.Rules)
+				ruleIndex[diag.Code] = index
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRuleOf(diag.Code))
+			}
+			result.RuleID = diag.Code
+			result.RuleIndex = &index
+		}
+
+		for i, related := range diag.Related {
+			location := r.location(related.Pointer, related.Msg)
+			id := i + 1
+			location.ID = &id
+			result.RelatedLocations = append(result.RelatedLocations, location)
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	return enc.Encode(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []*sarifRun{run},
+	})
+}
+
+// location converts a diagnostic pointer to a SARIF location spanning the whole pointer.
+func (r *SARIFReporter) location(pointer *DiagnosticPointer, msg string) *sarifLocation {
+	path, _ := pointer.Loc.GetIdx().Fullpath()
+
+	// Without its line, e.g. for a deleted file, a column is given as is
+	line, _ := r.cache.Line(&pointer.Loc)
+	region := &sarifRegion{
+		StartLine:   pointer.Loc.Line,
+		StartColumn: utf16Column(line, int(pointer.Loc.Column)),
+	}
+	if pointer.Length > 0 {
+		region.EndLine = pointer.Loc.Line
+		region.EndColumn = utf16Column(line, int(pointer.Loc.Column)+pointer.Length)
+	}
+
+	location := &sarifLocation{
+		PhysicalLocation: &sarifPhysicalLocation{
+			ArtifactLocation: r.artifactLocation(path),
+			Region:           region,
+		},
+	}
+	if msg != "" {
+		location.Message = &sarifMessage{Text: msg}
+	}
+	return location
+}
+
+// utf16Column converts a column of the lexer, which counts bytes and tabs as tabWidth,
+// to UTF-16 code units of the line. Columns past the end of the line count bytes.
+func utf16Column(line string, column int) int {
+	lexer, units := 1, 1
+	for i := 0; i < len(line) && lexer < column; {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if r == '\t' {
+			lexer += tabWidth
+		} else {
+			lexer += size
+		}
+		// An invalid byte is decoded as U+FFFD, a single unit
+		units += utf16.RuneLen(r)
+		i += size
+	}
+	return units + max(column-lexer, 0)
+}
+
+// artifactLocation references a file relative to the mod root if it lies under it.
+func (r *SARIFReporter) artifactLocation(path string) *sarifArtifactLocation {
+	if r.root != "" {
+		rel, err := filepath.Rel(r.root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return &sarifArtifactLocation{
+				URI:       (&url.URL{Path: filepath.ToSlash(rel)}).String(),
+				URIBaseID: sarifRootID,
+			}
+		}
+	}
+	return &sarifArtifactLocation{URI: fileURI(path)}
+}
+
+// fileURI returns the file:// URI of an absolute path.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows drive letters, e.g. file:///C:/mods
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// sarifRuleOf describes a diagnostic code with its catalog entry.
+func sarifRuleOf(code codes.Code) *sarifRule {
+	rule := &sarifRule{ID: string(code)}
+	if entry, ok := codes.Lookup(code); ok {
+		rule.ShortDescription = &sarifMultiformatMessage{Text: entry.Title}
+		rule.FullDescription = &sarifMultiformatMessage{Text: entry.Explanation}
+		rule.DefaultConfiguration = &sarifReportingConfiguration{Level: sarifLevel(entry.Severity)}
+	}
+	return rule
+}
+
+// sarifText is the message of a result, followed by the notes and help of the diagnostic,
+// which SARIF has no place for.
+func sarifText(diag *DiagnosticItem) string {
+	lines := []string{diag.Msg}
+	for _, note := range diag.Notes {
+		lines = append(lines, "note: "+note)
+	}
+	if diag.Help != "" {
+		lines = append(lines, "help: "+diag.Help)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(sev severity.Severity) string {
+	switch sev {
+	case severity.Info:
+		return "note"
+	case severity.Warning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,414 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// TestSARIFReporter_Schema validates a log against the published SARIF 2.1.0 schema,
+// testdata/sarif-schema-2.1.0.json (OASIS errata 01).
+func TestSARIFReporter_Schema(t *testing.T) {
+	path, diagnostics := testDiagnostics(t)
+	diagnostics[0].Code = "P0001"
+	diagnostics[1].Code = "P0002"
+	diagnostics[1].Related = []*report.RelatedLocation{{
+		Pointer: diagnostics[0].Pointer,
+		Msg:     "block opened here",
+	}}
+
+	var buf bytes.Buffer
+	if err := report.NewSARIFReporter(&buf, filepath.Dir(path)).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+
+	schemaData, err := os.ReadFile(filepath.Join("testdata", "sarif-schema-2.1.0.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(schemaData, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	var log any
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+
+	v := &schemaValidator{root: schema}
+	v.validate("$", schema, log)
+	for _, e := range v.errs {
+		t.Error(e)
+	}
+}
+
+func TestSARIFReporter(t *testing.T) {
+	path, diagnostics := testDiagnostics(t)
+	diagnostics[0].Code = "P0001"
+	diagnostics[1].Code = "P0001"
+	diagnostics[1].Related = []*report.RelatedLocation{{
+		Pointer: diagnostics[0].Pointer,
+		Msg:     "block opened here",
+	}}
+
+	var buf bytes.Buffer
+	if err := report.NewSARIFReporter(&buf, filepath.Dir(path)).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			OriginalURIBaseIDs map[string]struct {
+				URI string `json:"uri"`
+			} `json:"originalUriBaseIds"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string `json:"uri"`
+							URIBaseID string `json:"uriBaseId"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+							EndLine     int `json:"endLine"`
+							EndColumn   int `json:"endColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				RelatedLocations []struct {
+					Message struct {
+						Text string `json:"text"`
+					} `json:"message"`
+				} `json:"relatedLocations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != "P0001" {
+		t.Errorf("rules = %+v, want a single P0001 rule", run.Tool.Driver.Rules)
+	}
+	if base := run.OriginalURIBaseIDs["MODROOT"].URI; !strings.HasPrefix(base, "file:///") || !strings.HasSuffix(base, "/") {
+		t.Errorf("MODROOT uri = %q, want an absolute file URI of a directory", base)
+	}
+
+	if len(run.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(run.Results))
+	}
+	first, second := run.Results[0], run.Results[1]
+	if first.Level != "error" || second.Level != "warning" {
+		t.Errorf("levels = %q, %q, want error, warning", first.Level, second.Level)
+	}
+	if second.RuleID != "P0001" || second.RuleIndex != 0 {
+		t.Errorf("second result rule = %q (%d), want P0001 (0)", second.RuleID, second.RuleIndex)
+	}
+
+	artifact := second.Locations[0].PhysicalLocation.ArtifactLocation
+	if artifact.URI != "00_traits.txt" || artifact.URIBaseID != "MODROOT" {
+		t.Errorf("artifact location = %+v, want 00_traits.txt relative to MODROOT", artifact)
+	}
+	// The tab before the number is a single UTF-16 unit, not 4 columns
+	region := second.Locations[0].PhysicalLocation.Region
+	if region.StartLine != 2 || region.StartColumn != 9 || region.EndLine != 2 || region.EndColumn != 16 {
+		t.Errorf("region = %+v, want 2:9-2:16", region)
+	}
+
+	if len(second.RelatedLocations) != 1 || second.RelatedLocations[0].Message.Text != "block opened here" {
+		t.Errorf("related locations = %+v", second.RelatedLocations)
+	}
+}
+
+func TestSARIFReporter_Columns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "00_traits.txt")
+	if err := os.WriteFile(path, []byte("brave = {\n\t\tname = \"\u00c7a\U0001F600\" cost = 1.23456\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Tabs count as 4 columns and characters by their bytes for the lexer
+	loc := *tokens.LocFromParadoxFile(files.NewParadoxTxtFile(path, files.Mod))
+	loc.Line, loc.Column = 2, 33
+	diagnostics := []*report.DiagnosticItem{{
+		Severity: severity.Warning,
+		Code:     codes.NumberPrecisionLoss,
+		Msg:      "Number has more than three decimals",
+		Pointer:  &report.DiagnosticPointer{Loc: loc, Length: 7},
+	}}
+
+	var buf bytes.Buffer
+	if err := report.NewSARIFReporter(&buf, filepath.Dir(path)).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			ColumnKind string `json:"columnKind"`
+			Results    []struct {
+				Locations []struct {
+					PhysicalLocation struct {
+						Region struct {
+							StartColumn int `json:"startColumn"`
+							EndColumn   int `json:"endColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	run := log.Runs[0]
+	if run.ColumnKind != "utf16CodeUnits" {
+		t.Errorf("columnKind = %q, want utf16CodeUnits", run.ColumnKind)
+	}
+	// The emoji is a surrogate pair
+	region := run.Results[0].Locations[0].PhysicalLocation.Region
+	if region.StartColumn != 24 || region.EndColumn != 31 {
+		t.Errorf("columns = %d-%d, want 24-31", region.StartColumn, region.EndColumn)
+	}
+}
+
+func TestSARIFReporter_OutsideRoot(t *testing.T) {
+	_, diagnostics := testDiagnostics(t)
+
+	var buf bytes.Buffer
+	if err := report.NewSARIFReporter(&buf, t.TempDir()).Report(diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"uri": "file:///`) || strings.Contains(buf.String(), `"uriBaseId"`) {
+		t.Errorf("files outside the root should have absolute URIs:\n%s", buf.String())
+	}
+}
+
+// schemaValidator checks a decoded JSON document against a draft-04 JSON Schema, with
+// the keywords used by the SARIF schema: $ref, type, enum, required, properties,
+// additionalProperties, items, minItems, uniqueItems, minimum, maximum, pattern, anyOf
+// and oneOf. The format keyword (uri, uri-reference and date-time) isn't checked.
+// Other keywords are reported, so the schema can't silently rely on them.
+type schemaValidator struct {
+	root map[string]any
+	errs []string
+}
+
+// schemaKeywords are the keywords the validator checks, or knowingly ignores.
+var schemaKeywords = map[string]bool{
+	"$ref": true, "type": true, "enum": true, "required": true, "properties": true,
+	"additionalProperties": true, "items": true, "minItems": true, "uniqueItems": true,
+	"minimum": true, "maximum": true, "pattern": true, "anyOf": true, "oneOf": true,
+	// Annotations
+	"$schema": true, "id": true, "title": true, "description": true, "default": true, "definitions": true,
+	// Not checked
+	"format": true,
+}
+
+func (v *schemaValidator) errorf(path, format string, args ...any) {
+	v.errs = append(v.errs, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *schemaValidator) resolve(schema map[string]any) map[string]any {
+	ref, ok := schema["$ref"].(string)
+	if !ok {
+		return schema
+	}
+	name := strings.TrimPrefix(ref, "#/definitions/")
+	def, ok := v.root["definitions"].(map[string]any)[name].(map[string]any)
+	if !ok {
+		v.errorf(ref, "unknown definition")
+		return map[string]any{}
+	}
+	return def
+}
+
+// matches reports whether a value is valid against a schema, without recording errors.
+func (v *schemaValidator) matches(path string, schema map[string]any, value any) bool {
+	sub := &schemaValidator{root: v.root}
+	sub.validate(path, schema, value)
+	return len(sub.errs) == 0
+}
+
+func (v *schemaValidator) validate(path string, schema map[string]any, value any) {
+	schema = v.resolve(schema)
+	for keyword := range schema {
+		if !schemaKeywords[keyword] {
+			v.errorf(path, "unsupported schema keyword %q", keyword)
+		}
+	}
+
+	if types, ok := schema["type"]; ok && !matchesType(types, value) {
+		v.errorf(path, "got %T, want type %v", value, types)
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, option := range enum {
+			if reflect.DeepEqual(option, value) {
+				found = true
+			}
+		}
+		if !found {
+			v.errorf(path, "%v is not one of %v", value, enum)
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, option := range anyOf {
+			matched = matched || v.matches(path, option.(map[string]any), value)
+		}
+		if !matched {
+			v.errorf(path, "does not match any of %v", anyOf)
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		matched := 0
+		for _, option := range oneOf {
+			if v.matches(path, option.(map[string]any), value) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.errorf(path, "matches %d of %v, want exactly one", matched, oneOf)
+		}
+	}
+
+	switch value := value.(type) {
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && value < min {
+			v.errorf(path, "%v is less than the minimum %v", value, min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && value > max {
+			v.errorf(path, "%v is greater than the maximum %v", value, max)
+		}
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				v.errorf(path, "invalid pattern %q: %v", pattern, err)
+			} else if !re.MatchString(value) {
+				v.errorf(path, "%q doesn't match the pattern %q", value, pattern)
+			}
+		}
+	case map[string]any:
+		v.validateObject(path, schema, value)
+	case []any:
+		if min, ok := schema["minItems"].(float64); ok && float64(len(value)) < min {
+			v.errorf(path, "has %d items, want at least %v", len(value), min)
+		}
+		if unique, _ := schema["uniqueItems"].(bool); unique {
+			for i := range value {
+				for j := range i {
+					if reflect.DeepEqual(value[i], value[j]) {
+						v.errorf(path, "items %d and %d are equal", j, i)
+					}
+				}
+			}
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				v.validate(fmt.Sprintf("%s[%d]", path, i), items, item)
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(path string, schema map[string]any, object map[string]any) {
+	if required, ok := schema["required"].([]any); ok {
+		for _, key := range required {
+			if _, ok := object[key.(string)]; !ok {
+				v.errorf(path, "missing required property %q", key)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		propPath := path + "." + key
+		if prop, ok := properties[key].(map[string]any); ok {
+			v.validate(propPath, prop, object[key])
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case map[string]any:
+			v.validate(propPath, additional, object[key])
+		case bool:
+			if !additional {
+				v.errorf(propPath, "property is not allowed by the schema")
+			}
+		}
+	}
+}
+
+func matchesType(types any, value any) bool {
+	switch types := types.(type) {
+	case string:
+		return matchesSingleType(types, value)
+	case []any:
+		for _, typ := range types {
+			if matchesSingleType(typ.(string), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesSingleType(typ string, value any) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "null":
+		return value == nil
+	}
+	return false
+}
@@ -1,6 +1,11 @@
 package severity
 
-import "github.com/fatih/color"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
 
 type Severity int
 
@@ -26,6 +31,29 @@ func (s Severity) String() string {
 	}
 }
 
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(s.String())), nil
+}
+
+// Parse parses the lowercase name of a severity, as written by MarshalText.
+func Parse(s string) (Severity, error) {
+	for _, sev := range []Severity{Info, Warning, Error, Critical} {
+		if strings.EqualFold(s, sev.String()) {
+			return sev, nil
+		}
+	}
+	return Info, fmt.Errorf("unknown severity %q (expected info, warning, error or critical)", s)
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	sev, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sev
+	return nil
+}
+
 func (sev Severity) Color() *color.Color {
 	switch sev {
 	case Severity(Error):
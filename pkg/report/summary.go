@@ -0,0 +1,49 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// Summary counts diagnostics per severity.
+type Summary map[severity.Severity]int
+
+// Summarize counts the diagnostics per severity.
+func Summarize(diagnostics []*DiagnosticItem) Summary {
+	s := make(Summary)
+	for _, diag := range diagnostics {
+		s[diag.Severity]++
+	}
+	return s
+}
+
+// AtLeast returns the number of diagnostics of the given severity or higher.
+func (s Summary) AtLeast(threshold severity.Severity) int {
+	n := 0
+	for sev, count := range s {
+		if sev >= threshold {
+			n += count
+		}
+	}
+	return n
+}
+
+// String formats the counts from the most severe, e.g. "1 critical, 2 errors, 0 warnings, 1 info".
+func (s Summary) String() string {
+	parts := []string{
+		fmt.Sprintf("%d critical", s[severity.Critical]),
+		plural(s[severity.Error], "error"),
+		plural(s[severity.Warning], "warning"),
+		fmt.Sprintf("%d info", s[severity.Info]),
+	}
+	return strings.Join(parts, ", ")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,251 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/unLomTrois/gock3/pkg/cache"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// contextLines is the number of source lines shown before and after the line of a diagnostic.
+const contextLines = 1
+
+// tabWidth matches the lexer, which counts a tab as 4 columns.
+const tabWidth = 4
+
+// TextReporter writes human-readable diagnostics in the style of compilers:
+//
+//	error[P0010]: Number "1.23456" has more than three decimals
+//	 --> [my_mod] common/traits/00_traits.txt:2:9
+//	  |
+//	1 | brave = {
+//	2 |     cost = 1.23456
+//	  |            ^^^^^^^
+//	3 | }
+//	  = help: round it to thousandths
+//
+// Related locations are underlined with '-' and their label, in the same excerpt when
+// they are in the same file. Files are shown relative to the game or mod they were
+// loaded from, if known.
+type TextReporter struct {
+	// AbsolutePaths shows the full paths of the files instead.
+	AbsolutePaths bool
+
+	w     io.Writer
+	cache *cache.ContentCache
+
+	gutter *color.Color
+	bold   *color.Color
+	colors bool
+}
+
+// NewTextReporter creates a TextReporter writing to w, colored according to mode.
+func NewTextReporter(w io.Writer, mode ColorMode) *TextReporter {
+	r := &TextReporter{
+		w:      w,
+		cache:  cache.NewContentCache(0),
+		gutter: color.New(color.FgBlue, color.Bold),
+		bold:   color.New(color.Bold),
+		colors: mode.Enabled(w),
+	}
+	r.colorize(r.gutter)
+	r.colorize(r.bold)
+	return r
+}
+
+// Report writes every diagnostic followed by an empty line.
+func (r *TextReporter) Report(diagnostics []*DiagnosticItem) error {
+	for _, diag := range diagnostics {
+		var sb strings.Builder
+		r.render(&sb, diag)
+		if _, err := io.WriteString(r.w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TextReporter) colorize(c *color.Color) *color.Color {
+	if r.colors {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+	return c
+}
+
+func (r *TextReporter) render(sb *strings.Builder, diag *DiagnosticItem) {
+	sevColor := r.colorize(diag.Severity.Color().Add(color.Bold))
+	loc := diag.Pointer.Loc
+
+	header := strings.ToLower(diag.Severity.String())
+	if diag.Code != "" {
+		header += "[" + string(diag.Code) + "]"
+	}
+	sb.WriteString(sevColor.Sprint(header))
+	sb.WriteString(r.bold.Sprint(": " + diag.Msg))
+	sb.WriteString("\n")
+
+	// The primary location with its context, and the related locations in the same
+	// file, share a snippet; related locations in other files get their own.
+	primary := &snippet{loc: loc}
+	primary.annotate(loc.Line, &annotation{pointer: diag.Pointer, marker: "^", color: sevColor})
+	for n := int(loc.Line) - contextLines; n <= int(loc.Line)+contextLines; n++ {
+		if n >= 1 {
+			primary.show(uint32(n))
+		}
+	}
+	snippets := []*snippet{primary}
+	for _, related := range diag.Related {
+		target := primary
+		if !related.Pointer.Loc.SameFile(loc) {
+			target = &snippet{loc: related.Pointer.Loc}
+			snippets = append(snippets, target)
+		}
+		target.annotate(related.Pointer.Loc.Line, &annotation{pointer: related.Pointer, marker: "-", label: related.Msg, color: r.gutter})
+		target.show(related.Pointer.Loc.Line)
+	}
+
+	width := len(strconv.Itoa(int(loc.Line)))
+	for _, s := range snippets {
+		s.lines, s.err = r.cache.Lines(s.loc.GetIdx())
+		if last := s.lastLine(); len(strconv.Itoa(int(last))) > width {
+			width = len(strconv.Itoa(int(last)))
+		}
+	}
+	pad := strings.Repeat(" ", width)
+
+	for i, s := range snippets {
+		arrow := "-->"
+		if i > 0 {
+			arrow = ":::"
+		}
+		fmt.Fprintf(sb, "%s%s %s\n", pad, r.gutter.Sprint(arrow), s.loc.Display(r.AbsolutePaths))
+		r.renderSnippet(sb, s, width)
+	}
+
+	for _, note := range diag.Notes {
+		fmt.Fprintf(sb, "%s %s %s\n", pad, r.gutter.Sprint("="), r.bold.Sprint("note: ")+note)
+	}
+	if diag.Help != "" {
+		fmt.Fprintf(sb, "%s %s %s\n", pad, r.gutter.Sprint("="), r.bold.Sprint("help: ")+diag.Help)
+	}
+	for _, fix := range diag.Fixes {
+		fmt.Fprintf(sb, "%s %s %s\n", pad, r.gutter.Sprint("="), r.bold.Sprint("fix: ")+fix.Msg+" (--fix)")
+	}
+	sb.WriteString("\n")
+}
+
+// snippet is an excerpt of a file with underlined locations.
+type snippet struct {
+	loc         tokens.Loc
+	shown       map[uint32]bool
+	annotations map[uint32][]*annotation
+
+	lines []string
+	err   error
+}
+
+// annotation underlines a pointer with a marker and an optional label.
+type annotation struct {
+	pointer *DiagnosticPointer
+	marker  string
+	label   string
+	color   *color.Color
+}
+
+func (s *snippet) show(line uint32) {
+	if s.shown == nil {
+		s.shown = make(map[uint32]bool)
+	}
+	s.shown[line] = true
+}
+
+func (s *snippet) annotate(line uint32, a *annotation) {
+	if s.annotations == nil {
+		s.annotations = make(map[uint32][]*annotation)
+	}
+	s.annotations[line] = append(s.annotations[line], a)
+}
+
+// visibleLines returns the shown lines that exist in the file, in order.
+func (s *snippet) visibleLines() []uint32 {
+	var lines []uint32
+	for line := range s.shown {
+		if s.err == nil && line >= 1 && int(line) <= len(s.lines) {
+			lines = append(lines, line)
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i] < lines[j] })
+	return lines
+}
+
+func (s *snippet) lastLine() uint32 {
+	lines := s.visibleLines()
+	if len(lines) == 0 {
+		return 0
+	}
+	return lines[len(lines)-1]
+}
+
+// renderSnippet writes the lines of a snippet, with "..." between distant lines.
+func (r *TextReporter) renderSnippet(sb *strings.Builder, s *snippet, width int) {
+	lines := s.visibleLines()
+	if len(lines) == 0 {
+		return
+	}
+	pad := strings.Repeat(" ", width)
+
+	fmt.Fprintf(sb, "%s %s\n", pad, r.gutter.Sprint("|"))
+	for i, n := range lines {
+		if i > 0 && n > lines[i-1]+1 {
+			fmt.Fprintf(sb, "%s\n", r.gutter.Sprint("..."))
+		}
+		text := strings.TrimRight(expandTabs(s.lines[n-1]), " ")
+		fmt.Fprintf(sb, "%s %s", r.gutter.Sprintf("%*d", width, n), r.gutter.Sprint("|"))
+		if text != "" {
+			sb.WriteString(" " + text)
+		}
+		sb.WriteString("\n")
+
+		for _, a := range s.annotations[n] {
+			offset, length := span(text, int(a.pointer.Loc.Column), a.pointer.Length)
+			underline := strings.Repeat(a.marker, length)
+			if a.label != "" {
+				underline += " " + a.label
+			}
+			fmt.Fprintf(sb, "%s %s %s%s\n", pad, r.gutter.Sprint("|"), strings.Repeat(" ", offset), a.color.Sprint(underline))
+		}
+	}
+}
+
+// span returns the visual offset and width of the caret underline of a pointer to
+// the given column of a line, whose tabs are already expanded.
+func span(text string, column, length int) (int, int) {
+	start := column - 1
+	if start > len(text) {
+		start = len(text)
+	}
+	end := start + length
+	if end > len(text) {
+		end = len(text)
+	}
+
+	offset := utf8.RuneCountInString(text[:start])
+	width := utf8.RuneCountInString(text[start:end])
+	if width < 1 {
+		width = 1
+	}
+	return offset, width
+}
+
+// expandTabs replaces tabs with spaces the way the lexer counts columns.
+func expandTabs(line string) string {
+	return strings.ReplaceAll(line, "\t", strings.Repeat(" ", tabWidth))
+}
@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+)
+
+// GoOptions controls the generated Go code.
+type GoOptions struct {
+	// Package is the name of the generated package.
+	Package string
+	// TypeName is the name of the type generated for top-level entries.
+	TypeName string
+}
+
+// GenerateGo returns Go type definitions for the schema, with "pdx" struct tags
+// understood by the decoder package. Entries decode into a map keyed by their id:
+//
+//	var entries map[string]TypeName
+//	err := decoder.Decode(tree.Block, &entries)
+func GenerateGo(s *Schema, opts GoOptions) ([]byte, error) {
+	g := &goGenerator{names: make(map[string]bool)}
+	g.names[opts.TypeName] = true
+	g.addStruct(opts.TypeName, s.Fields)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gock3 schema infer. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", opts.Package)
+
+	var imports []string
+	if g.usesAST {
+		imports = append(imports, `"github.com/unLomTrois/gock3/pkg/ast"`)
+	}
+	if g.usesValues {
+		imports = append(imports, `"github.com/unLomTrois/gock3/pkg/values"`)
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&buf, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+
+	for _, def := range g.defs {
+		buf.WriteString(def)
+		buf.WriteString("\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+type goGenerator struct {
+	defs       []string
+	names      map[string]bool
+	usesAST    bool
+	usesValues bool
+}
+
+// addStruct generates a struct type for a block, and the types of its nested blocks after it.
+func (g *goGenerator) addStruct(name string, fields []*Field) {
+	var sb strings.Builder
+	index := len(g.defs)
+	g.defs = append(g.defs, "")
+
+	fieldNames := make(map[string]bool)
+	fmt.Fprintf(&sb, "type %s struct {\n", name)
+	for _, field := range fields {
+		if field.Key == DateKey {
+			sb.WriteString("\t// Date-keyed blocks are not decoded.\n")
+			continue
+		}
+
+		fieldName := uniqueName(goName(field.Key), fieldNames)
+		fmt.Fprintf(&sb, "\t%s %s `pdx:%q`\n", fieldName, g.fieldType(name+fieldName, field), field.Key)
+	}
+	sb.WriteString("}\n")
+
+	g.defs[index] = sb.String()
+}
+
+// fieldType returns the Go type of a field, generating nested struct types as needed.
+func (g *goGenerator) fieldType(nestedName string, field *Field) string {
+	var typ string
+	switch field.Kind {
+	case KindBlock:
+		name := uniqueName(nestedName, g.names)
+		g.addStruct(name, field.Fields)
+		typ = name
+		if field.Optional && !field.Repeated {
+			typ = "*" + typ
+		}
+	case KindList:
+		typ = "[]" + g.scalarType(field.Elem)
+	case KindAny:
+		g.usesAST = true
+		typ = "ast.BlockOrValue"
+	default:
+		typ = g.scalarType(field.Kind)
+	}
+
+	if field.Repeated {
+		typ = "[]" + typ
+	}
+	return typ
+}
+
+func (g *goGenerator) scalarType(kind Kind) string {
+	switch kind {
+	case KindInt:
+		return "int"
+	case KindFixed:
+		g.usesValues = true
+		return "values.Fixed"
+	case KindBool:
+		return "bool"
+	case KindDate:
+		g.usesValues = true
+		return "values.Date"
+	default:
+		return "string"
+	}
+}
+
+// goName converts a key such as "ai_chance" or "scope:target" to an exported Go name.
+func goName(key string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+
+	name := sb.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// uniqueName returns name, or name with a numeric suffix if it is already taken.
+func uniqueName(name string, taken map[string]bool) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
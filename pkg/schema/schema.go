@@ -0,0 +1,225 @@
+// Package schema infers the shape of Paradox database files, such as the ones in
+// common/, from sample files and generates Go types for them.
+package schema
+
+import (
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// DateKey is the key under which all date-keyed fields (such as history
+// entries "941.1.1 = { ... }") of a block are collected.
+const DateKey = "<date>"
+
+// Kind is the inferred kind of a value.
+type Kind string
+
+const (
+	KindString Kind = "string"
+	KindInt    Kind = "int"
+	KindFixed  Kind = "fixed"
+	KindBool   Kind = "bool"
+	KindDate   Kind = "date"
+	// KindList is a block of literal values, e.g. "color = { 255 255 255 }".
+	KindList Kind = "list"
+	// KindBlock is a block of fields.
+	KindBlock Kind = "block"
+	// KindAny is used when a key holds both literal values and blocks.
+	KindAny Kind = "any"
+)
+
+// Schema describes the top-level entries of a set of files.
+type Schema struct {
+	// Entries is the number of top-level entries the schema was inferred from.
+	Entries int `json:"entries"`
+	// Fields describes the keys of the entries.
+	Fields []*Field `json:"fields"`
+}
+
+// Field describes one key of a block.
+type Field struct {
+	Key  string `json:"key"`
+	Kind Kind   `json:"kind"`
+	// Elem is the kind of the values of a list.
+	Elem Kind `json:"elem,omitempty"`
+	// Repeated is set when the key appears more than once in some block.
+	Repeated bool `json:"repeated"`
+	// Optional is set when the key is missing from some blocks.
+	Optional bool `json:"optional"`
+	// Count is the number of blocks the key appears in.
+	Count int `json:"count"`
+	// Fields describes the keys of block values.
+	Fields []*Field `json:"fields,omitempty"`
+}
+
+// Inferrer accumulates statistics about the entries of many files.
+type Inferrer struct {
+	entries *object
+}
+
+// NewInferrer creates an empty Inferrer.
+func NewInferrer() *Inferrer {
+	return &Inferrer{entries: newObject()}
+}
+
+// Add adds every top-level entry of the tree whose value is a block.
+func (inf *Inferrer) Add(tree *ast.AST) {
+	if tree == nil || tree.Block == nil {
+		return
+	}
+	for _, field := range tree.Block.Values {
+		if block, ok := field.Value.(*ast.FieldBlock); ok {
+			inf.entries.add(block)
+		}
+	}
+}
+
+// Schema returns the schema inferred from all entries added so far.
+func (inf *Inferrer) Schema() *Schema {
+	return &Schema{
+		Entries: inf.entries.count,
+		Fields:  inf.entries.fields(),
+	}
+}
+
+// Infer returns the schema of the top-level entries of the given trees.
+func Infer(trees ...*ast.AST) *Schema {
+	inf := NewInferrer()
+	for _, tree := range trees {
+		inf.Add(tree)
+	}
+	return inf.Schema()
+}
+
+// object holds the statistics of all the blocks found under one key.
+type object struct {
+	count int
+	order []string
+	keys  map[string]*stats
+}
+
+// stats holds the statistics of one key across all the blocks of an object.
+type stats struct {
+	count    int
+	repeated bool
+	kind     Kind
+	elem     Kind
+	block    *object
+}
+
+func newObject() *object {
+	return &object{keys: make(map[string]*stats)}
+}
+
+func (o *object) add(block *ast.FieldBlock) {
+	o.count++
+
+	counts := make(map[string]int)
+	for _, field := range block.Values {
+		key := field.Key.Value
+		if field.Key.Type == tokens.DATE {
+			key = DateKey
+		}
+
+		st, ok := o.keys[key]
+		if !ok {
+			st = &stats{}
+			o.keys[key] = st
+			o.order = append(o.order, key)
+		}
+
+		counts[key]++
+		if counts[key] == 1 {
+			st.count++
+		} else {
+			st.repeated = true
+		}
+		st.addValue(field.Value)
+	}
+}
+
+func (st *stats) addValue(value ast.BlockOrValue) {
+	switch v := value.(type) {
+	case *tokens.Token:
+		st.kind = mergeKinds(st.kind, kindOf(v))
+	case *ast.TokenBlock:
+		st.kind = mergeKinds(st.kind, KindList)
+		for _, token := range v.Values {
+			st.elem = mergeKinds(st.elem, kindOf(token))
+		}
+	case *ast.FieldBlock:
+		st.kind = mergeKinds(st.kind, KindBlock)
+		if st.block == nil {
+			st.block = newObject()
+		}
+		st.block.add(v)
+	}
+}
+
+func (o *object) fields() []*Field {
+	fields := make([]*Field, 0, len(o.order))
+	for _, key := range o.order {
+		st := o.keys[key]
+		field := &Field{
+			Key:      key,
+			Kind:     st.kind,
+			Repeated: st.repeated,
+			Optional: st.count < o.count,
+			Count:    st.count,
+		}
+		if st.kind == KindList {
+			field.Elem = st.elem
+		}
+		if st.kind == KindBlock && st.block != nil {
+			field.Fields = st.block.fields()
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// kindOf infers the kind of a literal token.
+func kindOf(token *tokens.Token) Kind {
+	switch token.Type {
+	case tokens.BOOL:
+		return KindBool
+	case tokens.DATE:
+		return KindDate
+	case tokens.NUMBER:
+		if _, err := token.IntValue(); err == nil {
+			return KindInt
+		}
+		return KindFixed
+	default:
+		return KindString
+	}
+}
+
+// mergeKinds returns the narrowest kind that can hold values of both kinds.
+func mergeKinds(a, b Kind) Kind {
+	switch {
+	case a == "" || a == b:
+		return b
+	case b == "":
+		return a
+	case isNumeric(a) && isNumeric(b):
+		return KindFixed
+	case isLiteral(a) && isLiteral(b):
+		return KindString
+	default:
+		return KindAny
+	}
+}
+
+func isNumeric(kind Kind) bool {
+	return kind == KindInt || kind == KindFixed
+}
+
+func isLiteral(kind Kind) bool {
+	switch kind {
+	case KindString, KindInt, KindFixed, KindBool, KindDate:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,84 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/testutil"
+	"github.com/unLomTrois/gock3/pkg/schema"
+)
+
+const traits = `brave = {
+	category = personality
+	opposites = { craven }
+	cost = 1
+	culture_modifier = { parameter = brave_bonus }
+	941.1.1 = { x = y }
+}
+craven = {
+	category = personality
+	opposites = { brave }
+	cost = 0.5
+	shown_in_ruler_designer = no
+	group = { a = b }
+	group = { a = c }
+}
+`
+
+func TestInfer(t *testing.T) {
+	s := schema.Infer(testutil.ParseString(t, traits))
+
+	if s.Entries != 2 {
+		t.Errorf("Entries = %d, want 2", s.Entries)
+	}
+
+	want := map[string]schema.Field{
+		"category":                {Kind: schema.KindString, Count: 2},
+		"opposites":               {Kind: schema.KindList, Elem: schema.KindString, Count: 2},
+		"cost":                    {Kind: schema.KindFixed, Count: 2},
+		"culture_modifier":        {Kind: schema.KindBlock, Optional: true, Count: 1},
+		"shown_in_ruler_designer": {Kind: schema.KindBool, Optional: true, Count: 1},
+		"group":                   {Kind: schema.KindBlock, Optional: true, Repeated: true, Count: 1},
+		schema.DateKey:            {Kind: schema.KindBlock, Optional: true, Count: 1},
+	}
+
+	if len(s.Fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(s.Fields), len(want))
+	}
+	for _, field := range s.Fields {
+		w, ok := want[field.Key]
+		if !ok {
+			t.Errorf("unexpected field %q", field.Key)
+			continue
+		}
+		if field.Kind != w.Kind || field.Elem != w.Elem || field.Optional != w.Optional ||
+			field.Repeated != w.Repeated || field.Count != w.Count {
+			t.Errorf("field %q = %+v, want %+v", field.Key, *field, w)
+		}
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	s := schema.Infer(testutil.ParseString(t, traits))
+
+	src, err := schema.GenerateGo(s, schema.GoOptions{Package: "traits", TypeName: "Trait"})
+	if err != nil {
+		t.Fatalf("GenerateGo() error = %v", err)
+	}
+
+	code := string(src)
+	for _, want := range []string{
+		"package traits",
+		"type Trait struct",
+		"Category string `pdx:\"category\"`",
+		"Opposites []string `pdx:\"opposites\"`",
+		"Cost values.Fixed `pdx:\"cost\"`",
+		"CultureModifier *TraitCultureModifier `pdx:\"culture_modifier\"`",
+		"Group []TraitGroup `pdx:\"group\"`",
+		"type TraitCultureModifier struct",
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(code), " "), want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Package suppress silences diagnostics with comments in the checked files:
+//
+//	# gock3:ignore P0003            ignores P0003 on the next line
+//	# gock3:ignore-block            ignores every code in the next field, including its block
+//	# gock3:ignore-file V0001 P0010 ignores V0001 and P0010 in the whole file
+//
+// Line and block directives apply to the line right below them, directives stacked on
+// consecutive lines all apply to the line below the last one. Codes are separated by
+// spaces or commas; a directive without codes ignores every code, and one naming an
+// unknown code ignores nothing.
+// Suppressions that match no diagnostic of the checks that ran are reported, so they
+// don't outlive the problem they silenced.
+package suppress
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/tokens"
+)
+
+// Prefix starts every directive, after the comment sign.
+const Prefix = "gock3:"
+
+const (
+	errUnusedSuppression = "Suppression %q doesn't match any diagnostic"
+	errUnknownDirective  = "Unknown suppression directive %q, expected ignore, ignore-block or ignore-file"
+	errUnknownCode       = "Unknown diagnostic code %q in suppression"
+)
+
+// Kind is the scope of a directive.
+type Kind string
+
+const (
+	// Line ignores diagnostics on the next line with code.
+	Line Kind = "ignore"
+	// Block ignores diagnostics in the next field, from its key to the end of its block.
+	Block Kind = "ignore-block"
+	// File ignores diagnostics in the whole file.
+	File Kind = "ignore-file"
+)
+
+// Directive is a single suppression comment.
+type Directive struct {
+	Kind Kind
+	// Codes lists the suppressed codes, every code if empty.
+	Codes   []codes.Code
+	Comment *tokens.Token
+	// FromLine and ToLine are the lines the directive applies to, ignored for File directives.
+	FromLine uint32
+	ToLine   uint32
+
+	used bool
+}
+
+// Matches reports whether the directive suppresses the diagnostic.
+func (d *Directive) Matches(diag *report.DiagnosticItem) bool {
+	loc := diag.Pointer.Loc
+	if !d.Comment.Loc.SameFile(loc) {
+		return false
+	}
+	if d.Kind != File && (loc.Line < d.FromLine || loc.Line > d.ToLine) {
+		return false
+	}
+	if len(d.Codes) == 0 {
+		return true
+	}
+	for _, code := range d.Codes {
+		if code == diag.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// Set holds the directives of a file.
+type Set struct {
+	Directives  []*Directive
+	diagnostics []*report.DiagnosticItem
+}
+
+// Scan collects the directives in the comments of a parsed file. Invalid directives
+// are reported by Diagnostics.
+func Scan(tree *ast.AST) *Set {
+	s := &Set{}
+	if tree == nil {
+		return s
+	}
+
+	for _, comment := range tree.Comments {
+		if directive := s.parse(comment); directive != nil {
+			s.Directives = append(s.Directives, directive)
+		}
+	}
+
+	// Directives apply to the line right after them, or to the target of the
+	// directive on that line, so they can be stacked.
+	targets := make(map[uint32]uint32)
+	for i := len(s.Directives) - 1; i >= 0; i-- {
+		directive := s.Directives[i]
+		if directive.Kind == File {
+			continue
+		}
+
+		line := directive.Comment.Loc.Line + 1
+		if target, ok := targets[line]; ok {
+			line = target
+		}
+		targets[directive.Comment.Loc.Line] = line
+
+		directive.FromLine, directive.ToLine = line, line
+		if directive.Kind == Block && tree.Block != nil {
+			directive.ToLine = blockEnd(tree.Block, line)
+		}
+	}
+
+	return s
+}
+
+// parse parses a comment, returning nil if it is not a valid directive.
+func (s *Set) parse(comment *tokens.Token) *Directive {
+	text := strings.TrimSpace(strings.TrimPrefix(comment.Value, "#"))
+	if !strings.HasPrefix(text, Prefix) {
+		return nil
+	}
+
+	parts := strings.FieldsFunc(strings.TrimPrefix(text, Prefix), func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+	if len(parts) == 0 {
+		parts = []string{""}
+	}
+
+	directive := &Directive{Kind: Kind(parts[0]), Comment: comment}
+	switch directive.Kind {
+	case Line, Block, File:
+	default:
+		errMsg := fmt.Sprintf(errUnknownDirective, parts[0])
+		s.diagnostics = append(s.diagnostics, report.FromToken(comment, codes.InvalidSuppression, errMsg))
+		return nil
+	}
+
+	// A misspelled code makes the directive suppress nothing, rather than every code
+	valid := true
+	for _, part := range parts[1:] {
+		entry, ok := codes.Lookup(codes.Code(part))
+		if !ok {
+			errMsg := fmt.Sprintf(errUnknownCode, part)
+			s.diagnostics = append(s.diagnostics, report.FromToken(comment, codes.InvalidSuppression, errMsg))
+			valid = false
+			continue
+		}
+		directive.Codes = append(directive.Codes, entry.Code)
+	}
+	if !valid {
+		return nil
+	}
+
+	return directive
+}
+
+// Filter returns the diagnostics that no directive suppresses, and marks the
+// directives that suppressed something as used.
+func (s *Set) Filter(diagnostics []*report.DiagnosticItem) []*report.DiagnosticItem {
+	if len(s.Directives) == 0 {
+		return diagnostics
+	}
+
+	kept := make([]*report.DiagnosticItem, 0, len(diagnostics))
+	for _, diag := range diagnostics {
+		suppressed := false
+		for _, directive := range s.Directives {
+			if directive.Matches(diag) {
+				directive.used = true
+				suppressed = true
+			}
+		}
+		if !suppressed {
+			kept = append(kept, diag)
+		}
+	}
+	return kept
+}
+
+// Diagnostics reports invalid directives, and the directives that haven't suppressed
+// anything so far. Call it after filtering the diagnostics of every check of the file,
+// with the codes those checks report: a directive is only reported if all its codes
+// were checked, as it may silence a check that didn't run.
+func (s *Set) Diagnostics(checked []codes.Code) []*report.DiagnosticItem {
+	diagnostics := append([]*report.DiagnosticItem{}, s.diagnostics...)
+	for _, directive := range s.Directives {
+		if !directive.used && directive.coveredBy(checked) {
+			errMsg := fmt.Sprintf(errUnusedSuppression, strings.TrimSpace(directive.Comment.Value))
+			diagnostics = append(diagnostics, report.FromToken(directive.Comment, codes.UnusedSuppression, errMsg))
+		}
+	}
+	return diagnostics
+}
+
+// coveredBy reports whether every code of the directive is in checked. A directive
+// without codes is always covered.
+func (d *Directive) coveredBy(checked []codes.Code) bool {
+	for _, code := range d.Codes {
+		if !slices.Contains(checked, code) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply filters the diagnostics of a file through its suppression comments, and adds
+// the diagnostics about the suppressions themselves. checked lists the codes of the
+// checks that ran on the file, see Set.Diagnostics.
+func Apply(tree *ast.AST, diagnostics []*report.DiagnosticItem, checked []codes.Code) []*report.DiagnosticItem {
+	s := Scan(tree)
+	kept := s.Filter(diagnostics)
+	return append(kept, s.Diagnostics(checked)...)
+}
+
+// blockEnd returns the last line of the first field whose key is on the given line.
+func blockEnd(block *ast.FieldBlock, line uint32) uint32 {
+	for _, field := range block.Values {
+		start, end := field.Key.Loc.Line, fieldEnd(field)
+		if start == line {
+			return end
+		}
+		if nested, ok := field.Value.(*ast.FieldBlock); ok && start < line && line <= end {
+			return blockEnd(nested, line)
+		}
+	}
+	return line
+}
+
+// fieldEnd returns the last line of a field, the end of the file for unclosed blocks.
+func fieldEnd(field *ast.Field) uint32 {
+	var end tokens.Loc
+	switch value := field.Value.(type) {
+	case *ast.FieldBlock:
+		end = value.End
+	case *ast.TokenBlock:
+		end = value.End
+	default:
+		return field.Key.Loc.Line
+	}
+
+	if end.Line == 0 {
+		return math.MaxUint32
+	}
+	return end.Line
+}
@@ -0,0 +1,142 @@
+package suppress_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+	"github.com/unLomTrois/gock3/pkg/suppress"
+	"github.com/unLomTrois/gock3/pkg/validator"
+)
+
+// check parses the content, runs the duplicate keys check and applies the suppressions.
+func check(t *testing.T, content string) []*report.DiagnosticItem {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, diagnostics, err := parser.ParseParadoxFile(files.NewParadoxTxtFile(path, files.Mod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies := &ast.Policies{Default: ast.FirstWins, DefaultBlock: &ast.Policies{Default: ast.FirstWins}}
+	diagnostics = append(diagnostics, validator.DuplicateKeys(tree.Block, policies)...)
+	checked := append(parser.Codes(), codes.RepeatedKey)
+	return suppress.Apply(tree, diagnostics, checked)
+}
+
+type finding struct {
+	code codes.Code
+	line uint32
+}
+
+func findings(diagnostics []*report.DiagnosticItem) []finding {
+	var result []finding
+	for _, diag := range diagnostics {
+		result = append(result, finding{diag.Code, diag.Pointer.Loc.Line})
+	}
+	return result
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []finding
+	}{
+		{
+			name:    "no suppressions",
+			content: "a = {\n\tcost = 0.12345\n\tcost = 2\n}\n",
+			want:    []finding{{codes.NumberPrecisionLoss, 2}, {codes.RepeatedKey, 3}},
+		},
+		{
+			name:    "next line",
+			content: "a = {\n\t# gock3:ignore P0010\n\tcost = 0.12345\n\tcost = 2\n}\n",
+			want:    []finding{{codes.RepeatedKey, 4}},
+		},
+		{
+			name:    "next line only",
+			content: "a = {\n\t# gock3:ignore P0010\n\tcost = 1\n\tbase = 0.12345\n}\n",
+			want:    []finding{{codes.NumberPrecisionLoss, 4}, {codes.UnusedSuppression, 2}},
+		},
+		{
+			name:    "other code",
+			content: "a = {\n\tcost = 0.12345\n\t# gock3:ignore P0010\n\tcost = 2\n}\n",
+			want:    []finding{{codes.NumberPrecisionLoss, 2}, {codes.RepeatedKey, 4}, {codes.UnusedSuppression, 3}},
+		},
+		{
+			name:    "several codes",
+			content: "a = {\n\tcost = 1\n\t# gock3:ignore P0010, V0001\n\tcost = 0.12345\n}\n",
+			want:    nil,
+		},
+		{
+			name:    "stacked",
+			content: "a = {\n\tcost = 1\n\t# gock3:ignore P0010\n\t# gock3:ignore V0001\n\tcost = 0.12345\n}\n",
+			want:    nil,
+		},
+		{
+			name:    "block",
+			content: "# gock3:ignore-block\na = {\n\tcost = 0.12345\n\tcost = 2\n\tb = {\n\t\tx = 1\n\t\tx = 2\n\t}\n}\nc = 0.12345\n",
+			want:    []finding{{codes.NumberPrecisionLoss, 10}},
+		},
+		{
+			name:    "nested block",
+			content: "a = {\n\tcost = 0.12345\n\t# gock3:ignore-block V0001\n\tb = {\n\t\tx = 1\n\t\tx = 2\n\t}\n\tcost = 2\n}\n",
+			want:    []finding{{codes.NumberPrecisionLoss, 2}, {codes.RepeatedKey, 8}},
+		},
+		{
+			name:    "file",
+			content: "# gock3:ignore-file p0010\na = {\n\tcost = 0.12345\n}\nb = 0.12345\nb = 1\n",
+			want:    []finding{{codes.RepeatedKey, 6}},
+		},
+		{
+			name:    "unused file suppression",
+			content: "# gock3:ignore-file V0001\na = 1\n",
+			want:    []finding{{codes.UnusedSuppression, 1}},
+		},
+		{
+			// The check reporting V0003 didn't run, it may have used the suppression
+			name:    "unchecked code",
+			content: "# gock3:ignore-file V0003\n# gock3:ignore V0001 V0003\na = 1\n",
+			want:    nil,
+		},
+		{
+			name:    "invalid directive and code",
+			content: "# gock3:ignore-line P0010\n# gock3:ignore P9999 P0010\na = 0.12345\n",
+			want:    []finding{{codes.NumberPrecisionLoss, 3}, {codes.InvalidSuppression, 1}, {codes.InvalidSuppression, 2}},
+		},
+		{
+			// Not a directive without codes, which would ignore every code
+			name:    "unknown codes only",
+			content: "# gock3:ignore P9999\na = 0.12345\n",
+			want:    []finding{{codes.NumberPrecisionLoss, 2}, {codes.InvalidSuppression, 1}},
+		},
+		{
+			name:    "plain comments",
+			content: "# gock3 is a linter\na = 1 # not a directive\n",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findings(check(t, tt.content))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("finding %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
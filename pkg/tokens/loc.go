@@ -17,7 +17,7 @@ type Loc struct {
 
 // Filename возвращает имя файла из Loc
 func (loc *Loc) Filename() (string, error) {
-	path, err := files.PATHTABLE.LookupFullpath(loc.idx)
+	path, err := loc.idx.Fullpath()
 	if err != nil {
 		return "", err
 	}
@@ -25,18 +25,31 @@ func (loc *Loc) Filename() (string, error) {
 	return filename, nil
 }
 
-// Pathname возвращает относительный путь из Loc
+// Pathname возвращает путь из Loc относительно корня файла (игры или мода),
+// или полный путь, если корень неизвестен
 func (loc *Loc) Pathname() (string, error) {
-	path, err := files.PATHTABLE.LookupFullpath(loc.idx)
-	if err != nil {
-		return "", err
+	return loc.idx.Relpath()
+}
+
+// Display возвращает позицию для вывода пользователю: имя корня и путь относительно
+// него, например "[vanilla] common/traits/00_traits.txt:12:3", или полный путь, если
+// absolute или корень неизвестен
+func (loc *Loc) Display(absolute bool) string {
+	root, ok := loc.idx.Root()
+	if absolute || !ok {
+		fullpath, err := loc.Fullpath()
+		if err != nil {
+			return fmt.Sprintf("%d:%d", loc.Line, loc.Column)
+		}
+		return fullpath
 	}
-	return path, nil
+	path, _ := loc.idx.Relpath()
+	return fmt.Sprintf("[%s] %s:%d:%d", root.Name, path, loc.Line, loc.Column)
 }
 
 // Fullpath возвращает полный путь из Loc
 func (loc *Loc) Fullpath() (string, error) {
-	fullpath, err := files.PATHTABLE.LookupFullpath(loc.idx)
+	fullpath, err := loc.idx.Fullpath()
 	if err != nil {
 		return "", err
 	}
@@ -3,6 +3,8 @@ package tokens
 import (
 	"fmt"
 	"strconv"
+
+	"github.com/unLomTrois/gock3/pkg/values"
 )
 
 type Token struct {
@@ -45,3 +47,24 @@ func (t *Token) Is(value string) bool {
 func (t *Token) FloatValue() (float64, error) {
 	return strconv.ParseFloat(t.Value, 64)
 }
+
+// IntValue parses the token as an integer
+func (t *Token) IntValue() (int64, error) {
+	return values.ParseInt(t.Value)
+}
+
+// FixedValue parses the token as a fixed-point number;
+// an error wrapping values.ErrPrecisionLoss comes with a usable, truncated value
+func (t *Token) FixedValue() (values.Fixed, error) {
+	return values.ParseFixed(t.Value)
+}
+
+// DateValue parses the token as a date, rejecting dates that don't exist
+func (t *Token) DateValue() (values.Date, error) {
+	return values.ParseDate(t.Value)
+}
+
+// BoolValue parses the token as a yes/no boolean
+func (t *Token) BoolValue() (bool, error) {
+	return values.ParseBool(t.Value)
+}
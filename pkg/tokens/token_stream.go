@@ -1,7 +1,9 @@
 package tokens
 
 type TokenStream struct {
-	Tokens   []*Token
+	Tokens []*Token
+	// Comments holds the comments of the input, which are not part of the syntax
+	Comments []*Token
 	Position int
 }
 
@@ -18,6 +20,12 @@ func (ts *TokenStream) Push(token *Token) *TokenStream {
 	return ts
 }
 
+func (ts *TokenStream) PushComment(token *Token) *TokenStream {
+	ts.Comments = append(ts.Comments, token)
+
+	return ts
+}
+
 func (ts *TokenStream) Next() *Token {
 	if ts.Position >= len(ts.Tokens) {
 		return nil
@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+)
+
+const (
+	errDeprecatedKey = "Key %q is deprecated, use %q instead"
+	fixDeprecatedKey = "rename to %q"
+)
+
+// DeprecatedKeys reports keys that were renamed by a game update, with a fix renaming
+// them. Renames maps each deprecated key to its replacement, in any block of the file.
+func DeprecatedKeys(block *ast.FieldBlock, renames map[string]string) []*report.DiagnosticItem {
+	var diagnostics []*report.DiagnosticItem
+	checkDeprecatedKeys(block, renames, &diagnostics)
+	return diagnostics
+}
+
+func checkDeprecatedKeys(block *ast.FieldBlock, renames map[string]string, diagnostics *[]*report.DiagnosticItem) {
+	if block == nil || len(renames) == 0 {
+		return
+	}
+
+	for _, field := range block.Values {
+		if replacement, ok := renames[field.Key.Value]; ok {
+			errMsg := fmt.Sprintf(errDeprecatedKey, field.Key.Value, replacement)
+			diag := report.FromToken(field.Key, codes.DeprecatedKey, errMsg)
+			diag.Fixes = []*report.Fix{{
+				Msg:   fmt.Sprintf(fixDeprecatedKey, replacement),
+				Edits: []*report.Edit{report.ReplaceToken(field.Key, replacement)},
+			}}
+			*diagnostics = append(*diagnostics, diag)
+		}
+
+		if nested, ok := field.Value.(*ast.FieldBlock); ok {
+			checkDeprecatedKeys(nested, renames, diagnostics)
+		}
+	}
+}
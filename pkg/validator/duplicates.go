@@ -0,0 +1,55 @@
+// Package validator checks parsed Paradox files for mistakes that are
+// syntactically valid but not what the game expects.
+package validator
+
+import (
+	"fmt"
+
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/codes"
+)
+
+const errRepeatedKey = "Key %q is repeated in the same block (first defined at line %d, column %d), only the %s definition takes effect"
+
+// DuplicateKeys reports keys that the policies declare single-valued but that
+// appear more than once in the same block. Nested blocks are checked with the
+// policies of their own context.
+func DuplicateKeys(block *ast.FieldBlock, policies *ast.Policies) []*report.DiagnosticItem {
+	var diagnostics []*report.DiagnosticItem
+	checkDuplicateKeys(block, policies, &diagnostics)
+	return diagnostics
+}
+
+func checkDuplicateKeys(block *ast.FieldBlock, policies *ast.Policies, diagnostics *[]*report.DiagnosticItem) {
+	if block == nil {
+		return
+	}
+
+	first := make(map[string]*ast.Field)
+	for _, field := range block.Values {
+		key := field.Key.Value
+		policy := policies.Of(key)
+
+		if prev, seen := first[key]; seen && policy.SingleValued() {
+			which := "first"
+			if policy == ast.LastWins {
+				which = "last"
+			}
+			prevLoc := prev.GetLoc()
+			errMsg := fmt.Sprintf(errRepeatedKey, key, prevLoc.Line, prevLoc.Column, which)
+			diag := report.FromToken(field.Key, codes.RepeatedKey, errMsg)
+			diag.Related = []*report.RelatedLocation{{
+				Pointer: &report.DiagnosticPointer{Loc: prev.Key.Loc, Length: len(prev.Key.Value)},
+				Msg:     "first defined here",
+			}}
+			*diagnostics = append(*diagnostics, diag)
+		} else if !seen {
+			first[key] = field
+		}
+
+		if nested, ok := field.Value.(*ast.FieldBlock); ok {
+			checkDuplicateKeys(nested, policies.Block(key), diagnostics)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package validator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/testutil"
+	"github.com/unLomTrois/gock3/pkg/ast"
+	"github.com/unLomTrois/gock3/pkg/validator"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		policies  *ast.Policies
+		wantLines []uint32
+	}{
+		{
+			name:      "repeated single-valued attribute",
+			content:   "70027 = {\n\tculture = castilian\n\ttrait = brave\n\ttrait = just\n\tculture = leonese\n}\n",
+			policies:  validator.CharacterHistory,
+			wantLines: []uint32{5},
+		},
+		{
+			name:      "repeated dated blocks are allowed",
+			content:   "70027 = {\n\t941.1.1 = { birth = yes }\n\t941.1.1 = { trait = brave }\n}\n",
+			policies:  validator.CharacterHistory,
+			wantLines: nil,
+		},
+		{
+			name:      "repeated key inside a dated block",
+			content:   "70027 = {\n\t941.1.1 = {\n\t\tbirth = yes\n\t\tbirth = yes\n\t}\n}\n",
+			policies:  validator.CharacterHistory,
+			wantLines: []uint32{4},
+		},
+		{
+			name:      "repeated entries",
+			content:   "1 = { name = a }\n1 = { name = b }\n",
+			policies:  validator.CharacterHistory,
+			wantLines: []uint32{2},
+		},
+		{
+			name:      "event options and merged triggers",
+			content:   "test.1 = {\n\ttype = character_event\n\ttrigger = { a = yes }\n\ttrigger = { b = yes }\n\toption = { name = a }\n\toption = { name = b }\n\ttype = letter_event\n}\n",
+			policies:  validator.Events,
+			wantLines: []uint32{7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := testutil.ParseString(t, tt.content)
+			got := validator.DuplicateKeys(tree.Block, tt.policies)
+
+			if len(got) != len(tt.wantLines) {
+				t.Fatalf("DuplicateKeys() returned %d diagnostics, want %d", len(got), len(tt.wantLines))
+			}
+			for i, line := range tt.wantLines {
+				if got[i].Pointer.Loc.Line != line {
+					t.Errorf("diagnostic %d at line %d, want %d", i, got[i].Pointer.Loc.Line, line)
+				}
+				if !strings.Contains(got[i].Msg, "first defined at line") {
+					t.Errorf("diagnostic %d message %q does not point at the first occurrence", i, got[i].Msg)
+				}
+			}
+		})
+	}
+}
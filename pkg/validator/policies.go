@@ -0,0 +1,69 @@
+package validator
+
+import "github.com/unLomTrois/gock3/pkg/ast"
+
+// CharacterHistory declares the duplicate-key semantics of history/characters files:
+// character entries keyed by id, whose attributes are overridden by later
+// definitions and whose dated blocks are applied in order.
+var CharacterHistory = &ast.Policies{
+	Default: ast.LastWins,
+	DefaultBlock: &ast.Policies{
+		Default: ast.List,
+		Keys: map[string]ast.KeyPolicy{
+			"name":                   ast.LastWins,
+			"dna":                    ast.LastWins,
+			"female":                 ast.LastWins,
+			"dynasty":                ast.LastWins,
+			"dynasty_house":          ast.LastWins,
+			"religion":               ast.LastWins,
+			"faith":                  ast.LastWins,
+			"culture":                ast.LastWins,
+			"father":                 ast.LastWins,
+			"mother":                 ast.LastWins,
+			"sexuality":              ast.LastWins,
+			"martial":                ast.LastWins,
+			"diplomacy":              ast.LastWins,
+			"intrigue":               ast.LastWins,
+			"stewardship":            ast.LastWins,
+			"learning":               ast.LastWins,
+			"prowess":                ast.LastWins,
+			"health":                 ast.LastWins,
+			"fertility":              ast.LastWins,
+			"disallow_random_traits": ast.LastWins,
+			"trait":                  ast.List,
+		},
+		// Dated blocks such as "941.1.1 = { ... }", which may be repeated.
+		DefaultBlock: &ast.Policies{
+			Default: ast.List,
+			Keys: map[string]ast.KeyPolicy{
+				"birth": ast.LastWins,
+				"death": ast.LastWins,
+				"name":  ast.LastWins,
+			},
+			DefaultBlock: &ast.Policies{Default: ast.List},
+		},
+	},
+}
+
+// Events declares the duplicate-key semantics of events files: events keyed by id,
+// with single-valued attributes, merged trigger and effect blocks and a list of options.
+var Events = &ast.Policies{
+	Default: ast.List,
+	DefaultBlock: &ast.Policies{
+		Default: ast.List,
+		Keys: map[string]ast.KeyPolicy{
+			"type":      ast.FirstWins,
+			"title":     ast.FirstWins,
+			"desc":      ast.FirstWins,
+			"theme":     ast.FirstWins,
+			"hidden":    ast.FirstWins,
+			"orphan":    ast.FirstWins,
+			"cooldown":  ast.FirstWins,
+			"trigger":   ast.Merge,
+			"immediate": ast.Merge,
+			"after":     ast.Merge,
+			"option":    ast.List,
+		},
+		DefaultBlock: &ast.Policies{Default: ast.List},
+	},
+}
@@ -0,0 +1,116 @@
+package values
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// daysInMonth holds the length of each month. The game calendar has no leap years.
+var daysInMonth = [12]int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// Date is a game date such as 941.1.1.
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// NewDate returns the given date, or an error if it does not exist in the game calendar.
+func NewDate(year, month, day int) (Date, error) {
+	date := Date{Year: year, Month: month, Day: day}
+	if err := date.Validate(); err != nil {
+		return Date{}, err
+	}
+	return date, nil
+}
+
+// ParseDate parses a date written as "year.month.day". The day, or both month and
+// day, may be omitted ("941.1", "941.1.", "941") and default to the first.
+func ParseDate(s string) (Date, error) {
+	parts := strings.Split(strings.TrimSuffix(s, "."), ".")
+	if len(parts) > 3 {
+		return Date{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	fields := [3]int{0, 1, 1}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return Date{}, fmt.Errorf("invalid date %q", s)
+		}
+		fields[i] = n
+	}
+
+	date, err := NewDate(fields[0], fields[1], fields[2])
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: %w", s, err)
+	}
+	return date, nil
+}
+
+// Validate reports whether the date exists in the game calendar.
+func (d Date) Validate() error {
+	if d.Month < 1 || d.Month > 12 {
+		return fmt.Errorf("month %d is out of range 1-12", d.Month)
+	}
+	if days := daysInMonth[d.Month-1]; d.Day < 1 || d.Day > days {
+		return fmt.Errorf("day %d is out of range 1-%d for month %d", d.Day, days, d.Month)
+	}
+	return nil
+}
+
+// Compare returns -1 if d is before other, 1 if it is after, and 0 if they are equal.
+func (d Date) Compare(other Date) int {
+	switch {
+	case d.Year != other.Year:
+		return sign(d.Year - other.Year)
+	case d.Month != other.Month:
+		return sign(d.Month - other.Month)
+	default:
+		return sign(d.Day - other.Day)
+	}
+}
+
+// Before reports whether d is strictly before other.
+func (d Date) Before(other Date) bool {
+	return d.Compare(other) < 0
+}
+
+// After reports whether d is strictly after other.
+func (d Date) After(other Date) bool {
+	return d.Compare(other) > 0
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+func (d Date) String() string {
+	return fmt.Sprintf("%d.%d.%d", d.Year, d.Month, d.Day)
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+	date, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
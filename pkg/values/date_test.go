@@ -0,0 +1,67 @@
+package values
+
+import "testing"
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Date
+		wantErr bool
+	}{
+		{name: "full date", input: "941.1.1", want: Date{941, 1, 1}},
+		{name: "two-digit month and day", input: "1066.12.25", want: Date{1066, 12, 25}},
+		{name: "trailing dot", input: "941.3.", want: Date{941, 3, 1}},
+		{name: "year and month", input: "941.3", want: Date{941, 3, 1}},
+		{name: "year only", input: "867", want: Date{867, 1, 1}},
+		{name: "negative year", input: "-50.6.1", want: Date{-50, 6, 1}},
+		{name: "last day of february", input: "1000.2.28", want: Date{1000, 2, 28}},
+		{name: "no leap years", input: "1000.2.29", wantErr: true},
+		{name: "month out of range", input: "941.13.1", wantErr: true},
+		{name: "day out of range", input: "941.4.31", wantErr: true},
+		{name: "zero day", input: "941.1.0", wantErr: true},
+		{name: "too many parts", input: "941.1.1.1", wantErr: true},
+		{name: "not a number", input: "941.a.1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDate_Compare(t *testing.T) {
+	tests := []struct {
+		a, b Date
+		want int
+	}{
+		{Date{941, 1, 1}, Date{941, 1, 1}, 0},
+		{Date{941, 1, 1}, Date{997, 1, 1}, -1},
+		{Date{1066, 9, 15}, Date{1066, 9, 14}, 1},
+		{Date{1066, 10, 1}, Date{1066, 9, 30}, 1},
+		{Date{-5, 1, 1}, Date{5, 1, 1}, -1},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("%v.Compare(%v) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := tt.a.Before(tt.b); got != (tt.want < 0) {
+			t.Errorf("%v.Before(%v) = %v", tt.a, tt.b, got)
+		}
+		if got := tt.a.After(tt.b); got != (tt.want > 0) {
+			t.Errorf("%v.After(%v) = %v", tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestDate_String(t *testing.T) {
+	if got := (Date{1066, 9, 15}).String(); got != "1066.9.15" {
+		t.Errorf("String() = %q, want %q", got, "1066.9.15")
+	}
+}
@@ -0,0 +1,118 @@
+package values
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FixedScale is the number of fixed-point units in 1. The engine keeps three decimals.
+const FixedScale = 1000
+
+// fixedDecimals is the number of decimals a Fixed can represent.
+const fixedDecimals = 3
+
+// ErrPrecisionLoss is returned (wrapped) by ParseFixed when a number has more
+// decimals than the engine keeps. The returned value is still usable.
+var ErrPrecisionLoss = errors.New("precision beyond 0.001 is lost")
+
+// Fixed is a fixed-point number as used by the engine for script values,
+// stored as a count of thousandths.
+type Fixed int64
+
+// NewFixed converts an integer to a Fixed.
+func NewFixed(n int64) Fixed {
+	return Fixed(n * FixedScale)
+}
+
+// ParseFixed parses a decimal literal such as "0.5" or "-12.125".
+//
+// Decimals beyond the third are dropped. In that case the truncated value is
+// returned together with an error wrapping ErrPrecisionLoss.
+func ParseFixed(s string) (Fixed, error) {
+	text := s
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+
+	intPart, fracPart, _ := strings.Cut(text, ".")
+	if intPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+
+	whole, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || whole > math.MaxInt64/FixedScale {
+		return 0, fmt.Errorf("number %q is out of range", s)
+	}
+
+	var lost bool
+	if len(fracPart) > fixedDecimals {
+		lost = strings.Trim(fracPart[fixedDecimals:], "0") != ""
+		fracPart = fracPart[:fixedDecimals]
+	}
+	fracPart += strings.Repeat("0", fixedDecimals-len(fracPart))
+	frac, _ := strconv.ParseInt(fracPart, 10, 64)
+	if whole*FixedScale > math.MaxInt64-frac {
+		return 0, fmt.Errorf("number %q is out of range", s)
+	}
+
+	value := Fixed(whole*FixedScale + frac)
+	if negative {
+		value = -value
+	}
+
+	if lost {
+		return value, fmt.Errorf("number %q: %w", s, ErrPrecisionLoss)
+	}
+	return value, nil
+}
+
+// Float64 returns f as a floating-point number.
+func (f Fixed) Float64() float64 {
+	return float64(f) / FixedScale
+}
+
+// Int returns the integer part of f, truncated toward zero.
+func (f Fixed) Int() int64 {
+	return int64(f) / FixedScale
+}
+
+// String formats f without trailing zeros, e.g. "0.5" or "12".
+func (f Fixed) String() string {
+	n := int64(f)
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	whole, frac := n/FixedScale, n%FixedScale
+	if frac == 0 {
+		return fmt.Sprintf("%s%d", sign, whole)
+	}
+	fracText := strings.TrimRight(fmt.Sprintf("%03d", frac), "0")
+	return fmt.Sprintf("%s%d.%s", sign, whole, fracText)
+}
+
+func (f Fixed) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+func (f *Fixed) UnmarshalText(text []byte) error {
+	value, err := ParseFixed(string(text))
+	if err != nil && !errors.Is(err, ErrPrecisionLoss) {
+		return err
+	}
+	*f = value
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
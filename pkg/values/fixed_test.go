@@ -0,0 +1,78 @@
+package values
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseFixed(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     Fixed
+		wantLoss bool
+		wantErr  bool
+	}{
+		{name: "integer", input: "12", want: 12000},
+		{name: "decimal", input: "0.5", want: 500},
+		{name: "three decimals", input: "-12.125", want: -12125},
+		{name: "trailing zeros are not lost", input: "1.25000", want: 1250},
+		{name: "trailing dot", input: "3.", want: 3000},
+		{name: "precision loss", input: "0.0005", want: 0, wantLoss: true},
+		{name: "precision loss truncates", input: "-1.23456", want: -1234, wantLoss: true},
+		{name: "comma is not a decimal separator", input: "1,5", wantErr: true},
+		{name: "missing integer part", input: ".5", wantErr: true},
+		{name: "word", input: "yes", wantErr: true},
+		{name: "out of range", input: "99999999999999999999", wantErr: true},
+		{name: "largest", input: "9223372036854775.807", want: math.MaxInt64},
+		{name: "smallest", input: "-9223372036854775.807", want: -math.MaxInt64},
+		{name: "out of range by the decimals", input: "9223372036854775.808", wantErr: true},
+		{name: "out of range by the decimals, negative", input: "-9223372036854775.9", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFixed(tt.input)
+			loss := errors.Is(err, ErrPrecisionLoss)
+			if loss != tt.wantLoss {
+				t.Fatalf("ParseFixed(%q) precision loss = %v, want %v", tt.input, loss, tt.wantLoss)
+			}
+			if (err != nil && !loss) != tt.wantErr {
+				t.Fatalf("ParseFixed(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseFixed(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFixed_String(t *testing.T) {
+	tests := []struct {
+		value Fixed
+		want  string
+	}{
+		{12000, "12"},
+		{500, "0.5"},
+		{-12125, "-12.125"},
+		{-500, "-0.5"},
+		{1010, "1.01"},
+	}
+	for _, tt := range tests {
+		if got := tt.value.String(); got != tt.want {
+			t.Errorf("Fixed(%d).String() = %q, want %q", int64(tt.value), got, tt.want)
+		}
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	if got, err := ParseBool("yes"); err != nil || !got {
+		t.Errorf("ParseBool(yes) = %v, %v", got, err)
+	}
+	if got, err := ParseBool("no"); err != nil || got {
+		t.Errorf("ParseBool(no) = %v, %v", got, err)
+	}
+	if _, err := ParseBool("true"); err == nil {
+		t.Error("ParseBool(true) expected an error")
+	}
+}
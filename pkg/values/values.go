@@ -0,0 +1,37 @@
+// Package values provides typed representations of PDX script literal values:
+// dates, integers, fixed-point numbers and booleans.
+package values
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ParseInt parses an integer literal such as "70027" or "-5".
+func ParseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
+
+// ParseBool parses a "yes" or "no" literal.
+func ParseBool(s string) (bool, error) {
+	switch s {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean %q, expected yes or no", s)
+	}
+}
+
+// FormatBool formats a boolean the way PDX script spells it.
+func FormatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
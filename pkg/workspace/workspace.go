@@ -0,0 +1,125 @@
+// Package workspace holds the state shared by the files checked together, such as
+// their path table, the cache of their content, the roots their paths are shown
+// relative to and the open mod archives. Workspaces are independent of each other:
+// a long-running process, e.g. an editor server, can host several and drop one along
+// with its paths.
+package workspace
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/cache"
+	"github.com/unLomTrois/gock3/pkg/descriptor"
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+)
+
+// Workspace is a set of files checked together.
+type Workspace struct {
+	paths    *files.PathTable
+	cache    *cache.ContentCache
+	roots    []files.Root
+	archives []*files.Archive
+}
+
+// New creates an empty workspace. Its files are read through a cache of
+// cache.DefaultMaxBytes, see Cache.
+func New() *Workspace {
+	w := &Workspace{paths: files.NewPathTable(), cache: cache.NewContentCache(0)}
+	w.paths.SetReader(w.cache)
+	return w
+}
+
+// Cache returns the cache the files of the workspace are read through, for the
+// reporters to show them without reading them again.
+func (w *Workspace) Cache() *cache.ContentCache {
+	return w.cache
+}
+
+// Paths returns the path table of the workspace.
+func (w *Workspace) Paths() *files.PathTable {
+	return w.paths
+}
+
+// AddRoot adds a directory, e.g. the game or a mod, whose files are shown relative
+// to it. Add the roots before the files: a stored file keeps its root.
+func (w *Workspace) AddRoot(root files.Root) {
+	w.roots = append(w.roots, root)
+}
+
+// File returns a file of the workspace, in the innermost root containing it. Files
+// with the same path share their index in the path table, so their locations compare equal.
+func (w *Workspace) File(fullpath string, kind files.FileKind) *files.ParadoxTxtFile {
+	file := w.paths.File(fullpath, kind)
+
+	best := ""
+	for _, root := range w.roots {
+		if rel, ok := root.Rel(fullpath); ok && (file.Root() == nil || len(rel) < len(best)) {
+			file.SetRoot(root)
+			best = rel
+		}
+	}
+	return file
+}
+
+// ParseFiles parses files of the workspace concurrently, see parser.ParseFiles.
+func (w *Workspace) ParseFiles(fullpaths []string, kind files.FileKind, workers int) []*parser.FileResult {
+	paradoxFiles := make([]files.ParadoxFile, len(fullpaths))
+	for i, fullpath := range fullpaths {
+		paradoxFiles[i] = w.File(fullpath, kind)
+	}
+	return parser.ParseFiles(paradoxFiles, workers)
+}
+
+// OpenArchive opens a mod packed in a zip archive, see files.OpenArchive. The mod is
+// named by its descriptor.mod if it has one, and replaces its replace_path folders.
+// The archive stays open until Close.
+func (w *Workspace) OpenArchive(name string) (*files.ModRoot, error) {
+	archive, err := files.OpenArchive(name)
+	if err != nil {
+		return nil, err
+	}
+	w.archives = append(w.archives, archive)
+
+	mod := archive.Mod
+	fullpath := filepath.Join(mod.Path, descriptor.Filename)
+	if file, err := files.NewParadoxFSFile(mod.FS, descriptor.Filename, fullpath, files.Mod); err == nil {
+		if d, _, err := descriptor.ParseFile(file); err == nil {
+			if d.Name != "" {
+				mod.Name = d.Name
+			}
+			mod.ReplacePaths = d.ReplacePaths
+		}
+	}
+	return mod, nil
+}
+
+// ModFiles returns the script files of a mod, on disk or in an archive, sorted by
+// path. Their locations are shown relative to the mod.
+func (w *Workspace) ModFiles(mod *files.ModRoot) ([]*files.ParadoxTxtFile, error) {
+	entries, err := files.NewGameFS("", mod).Files(".")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*files.ParadoxTxtFile
+	for _, entry := range entries {
+		if strings.EqualFold(path.Ext(entry.Path), files.ScriptExt) {
+			result = append(result, w.paths.Add(entry.File()))
+		}
+	}
+	return result, nil
+}
+
+// Close closes the archives opened by the workspace.
+func (w *Workspace) Close() error {
+	var errs []error
+	for _, archive := range w.archives {
+		errs = append(errs, archive.Close())
+	}
+	w.archives = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,151 @@
+package workspace_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/files"
+	"github.com/unLomTrois/gock3/pkg/parser"
+	"github.com/unLomTrois/gock3/pkg/workspace"
+)
+
+func TestWorkspace_ParseFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "00_traits.txt")
+	if err := os.WriteFile(path, []byte("brave = {\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ws := workspace.New()
+	results := ws.ParseFiles([]string{path, path}, files.Mod, 2)
+	if len(results) != 2 || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if len(results[0].Diagnostics) == 0 || len(results[1].Diagnostics) == 0 {
+		t.Fatal("expected a missing brace diagnostic")
+	}
+
+	// Locations in the same file compare equal across parses
+	a, b := results[0].Diagnostics[0].Pointer.Loc, results[1].Diagnostics[0].Pointer.Loc
+	if a != b {
+		t.Errorf("locations %+v and %+v differ", a, b)
+	}
+	if ws.Paths().Len() != 1 {
+		t.Errorf("path table has %d paths, want 1", ws.Paths().Len())
+	}
+	// The file is read once, through the cache the reporters show it from
+	if ws.Cache().Len() != 1 {
+		t.Errorf("cache holds %d file(s), want 1", ws.Cache().Len())
+	}
+
+	// Another workspace has paths of its own
+	other := workspace.New()
+	c := other.ParseFiles([]string{path}, files.Mod, 1)[0].Diagnostics[0].Pointer.Loc
+	if c == a {
+		t.Errorf("workspaces share a location")
+	}
+	if got, err := other.Paths().LookupFullpath(c.GetIdx()); err != nil || got != path {
+		t.Errorf("LookupFullpath() = %v, %v", got, err)
+	}
+	if _, err := ws.Paths().LookupFullpath(c.GetIdx()); err == nil {
+		t.Errorf("expected an error looking up the path of another workspace")
+	}
+}
+
+func TestWorkspace_File(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mod", "common", "00_traits.txt")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ws := workspace.New()
+	ws.AddRoot(files.Root{Name: "outer", Path: dir})
+	ws.AddRoot(files.Root{Name: "inner", Path: filepath.Join(dir, "mod")})
+
+	root := ws.File(path, files.Mod).Root()
+	if root == nil || root.Name != "inner" {
+		t.Errorf("Root() = %+v, want the innermost root", root)
+	}
+	other := filepath.Join(t.TempDir(), "other.txt")
+	if err := os.WriteFile(other, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if root := ws.File(other, files.Mod).Root(); root != nil {
+		t.Errorf("Root() of a file outside of the roots = %+v", root)
+	}
+}
+
+func TestWorkspace_OpenArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mod.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"better_traits/descriptor.mod":              "name = \"Better Traits\"\nreplace_path = \"common/traits\"",
+		"better_traits/common/traits/00_traits.txt": "brave = {\n",
+		"better_traits/gfx/thumbnail.png":           "",
+	} {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	ws := workspace.New()
+	defer ws.Close()
+	mod, err := ws.OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.Name != "Better Traits" {
+		t.Errorf("mod name = %q, want the name of the descriptor", mod.Name)
+	}
+	if !reflect.DeepEqual(mod.ReplacePaths, []string{"common/traits"}) {
+		t.Errorf("replace paths = %v, want the replace_path of the descriptor", mod.ReplacePaths)
+	}
+
+	modFiles, err := ws.ModFiles(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modFiles) != 1 {
+		t.Fatalf("got %d script files, want 1", len(modFiles))
+	}
+
+	results := parser.ParseFiles([]files.ParadoxFile{modFiles[0]}, 1)
+	if results[0].Err != nil || len(results[0].Diagnostics) == 0 {
+		t.Fatalf("unexpected result %+v", results[0])
+	}
+	loc := results[0].Diagnostics[0].Pointer.Loc
+	want := "[Better Traits] common/traits/00_traits.txt:2:1"
+	if got := loc.Display(false); got != want {
+		t.Errorf("Display(false) = %q, want %q", got, want)
+	}
+	want = filepath.Join(path+"!", "better_traits", "common", "traits", "00_traits.txt") + ":2:1"
+	if got := loc.Display(true); got != want {
+		t.Errorf("Display(true) = %q, want %q", got, want)
+	}
+	if _, err := ws.Paths().LookupFullpath(loc.GetIdx()); err != nil {
+		t.Errorf("the file isn't in the path table of the workspace: %v", err)
+	}
+
+	if err := ws.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := modFiles[0].ReadFile(); err == nil {
+		t.Errorf("expected an error reading a closed archive")
+	}
+}